@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// AppLabels are the attributes of an application that AppsConfig.Labels may
+// add as labels, mapped to their name in /ws/v1/cluster/apps.
+var AppLabels = map[string]string{
+	"queue": "queue",
+	"user":  "user",
+	"name":  "name",
+	"type":  "applicationType",
+	"state": "state",
+}
+
+// AppsConfig enables the per-application metrics of a ResourceManager.
+type AppsConfig struct {
+	// TopN limits the applications exported to those allocating the most
+	// memory. Zero disables the per-application metrics.
+	TopN int
+	// Labels are the keys of AppLabels added to the application id.
+	Labels []string
+}
+
+// appAttributes are the numeric attributes of an application, scaled by
+// factor into base units.
+var appAttributes = []struct {
+	attr, name, help string
+	factor           float64
+}{
+	{"allocatedMB", "allocated_memory_bytes", "Memory allocated to the application's containers.", 1 << 20},
+	{"allocatedVCores", "allocated_vcores", "Virtual cores allocated to the application's containers.", 1},
+	{"runningContainers", "running_containers", "Running containers of the application.", 1},
+	{"progress", "progress", "Fraction of the application completed.", 0.01},
+	{"elapsedTime", "elapsed_seconds", "Time since the application started.", 0.001},
+}
+
+// appMetrics exports the running and accepted applications of a
+// ResourceManager, each labeled by its id and the configured labels.
+type appMetrics struct {
+	cfg        AppsConfig
+	attributes []*prometheus.Desc
+	omitted    *prometheus.Desc
+}
+
+func newAppMetrics(namespace string, cfg AppsConfig) *appMetrics {
+	labels := append([]string{"application"}, cfg.Labels...)
+	m := &appMetrics{
+		cfg: cfg,
+		omitted: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "apps", "omitted"),
+			"Running and accepted applications not exported as they are beyond the top N.",
+			nil, nil,
+		),
+	}
+	for _, a := range appAttributes {
+		m.attributes = append(m.attributes, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "app", a.name),
+			a.help,
+			labels, nil,
+		))
+	}
+	return m
+}
+
+// collect exports the apps of /ws/v1/cluster/apps allocating the most memory.
+func (m *appMetrics) collect(apps []jmx.Bean, ch chan<- prometheus.Metric) {
+	sort.SliceStable(apps, func(i, j int) bool {
+		a, _ := apps[i].Float("allocatedMB")
+		b, _ := apps[j].Float("allocatedMB")
+		return a > b
+	})
+	omitted := 0
+	if len(apps) > m.cfg.TopN {
+		omitted = len(apps) - m.cfg.TopN
+		apps = apps[:m.cfg.TopN]
+	}
+	ch <- prometheus.MustNewConstMetric(m.omitted, prometheus.GaugeValue, float64(omitted))
+	for _, app := range apps {
+		id, _ := app.String("id")
+		labelValues := []string{id}
+		for _, l := range m.cfg.Labels {
+			v, _ := app.String(AppLabels[l])
+			labelValues = append(labelValues, v)
+		}
+		for i, a := range appAttributes {
+			if v, ok := app.Float(a.attr); ok {
+				ch <- prometheus.MustNewConstMetric(m.attributes[i], prometheus.GaugeValue, v*a.factor, labelValues...)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// DataNode turns the numeric attributes of the beans served by the DataNode
+// /jmx servlet into metrics, see jmx.Mapper.
+type DataNode struct {
+	client *fetch.Client
+	url    string
+	mapper *jmx.Mapper
+	health *health
+	rpc    *rpcMetrics
+	jvm    *jvmMetrics
+}
+
+// NewDataNode returns a collector for the /jmx servlet at url.
+func NewDataNode(client *fetch.Client, url string, rules *jmx.Config) *DataNode {
+	return &DataNode{
+		client: client,
+		url:    url,
+		mapper: jmx.NewMapper("datanode", rules),
+		health: newHealth("datanode"),
+		rpc:    newRPCMetrics("datanode"),
+		jvm:    newJVMMetrics("datanode"),
+	}
+}
+
+// Describe implements the prometheus.Collector interface. The metrics depend
+// on the beans the DataNode serves, so none are described up front.
+func (c *DataNode) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *DataNode) Collect(ch chan<- prometheus.Metric) {
+	c.health.collect(ch, c.scrape)
+}
+
+func (c *DataNode) scrape(ch chan<- prometheus.Metric) error {
+	var r jmx.Response
+	if err := c.client.JSON(c.url, &r); err != nil {
+		return err
+	}
+	if r.Beans == nil {
+		return parseError("%s has no beans", c.url)
+	}
+	reset := c.mapper.Collect(&r, ch)
+	c.health.restart(reset, jvmStartTime(&r))
+	c.jvm.collect(&r, c.health, ch)
+	c.rpc.collect(&r, c.health, ch)
+	return nil
+}
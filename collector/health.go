@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// Stages of a scrape, used as the stage label of scrape_errors_total.
+const (
+	stageFetch  = "fetch"
+	stageDecode = "decode"
+	stageParse  = "parse"
+)
+
+// scrapeError is an error of a scrape annotated with its stage.
+type scrapeError struct {
+	stage string
+	err   error
+}
+
+func (e *scrapeError) Error() string {
+	return e.err.Error()
+}
+
+// parseError reports a document that decoded but does not look as expected.
+func parseError(format string, args ...interface{}) error {
+	return &scrapeError{stage: stageParse, err: fmt.Errorf(format, args...)}
+}
+
+func errorStage(err error) string {
+	switch err := err.(type) {
+	case *scrapeError:
+		return err.stage
+	case *fetch.DecodeError:
+		return stageDecode
+	}
+	return stageFetch
+}
+
+// health reports the outcome of the scrapes of a collector as
+// <namespace>_up, _scrape_duration_seconds, _scrape_errors_total and
+// _last_scrape_success_timestamp_seconds. Attributes that a Hadoop version
+// does not serve are counted in _missing_attribute_total instead of failing
+// the scrape, unless missing is nil.
+type health struct {
+	namespace   string
+	up          *prometheus.Desc
+	duration    *prometheus.Desc
+	lastSuccess *prometheus.Desc
+	errors      *prometheus.CounterVec
+	missing     *prometheus.CounterVec
+	restarts    prometheus.Counter
+
+	mtx             sync.Mutex
+	lastSuccessTime time.Time
+	startTime       float64
+}
+
+func newHealth(namespace string) *health {
+	h := &health{
+		namespace: namespace,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "up"),
+			"Whether the last scrape of the "+namespace+" succeeded.",
+			nil, nil,
+		),
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "scrape_duration_seconds"),
+			"Duration of the last scrape of the "+namespace+".",
+			nil, nil,
+		),
+		lastSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "last_scrape_success_timestamp_seconds"),
+			"Time of the last successful scrape of the "+namespace+".",
+			nil, nil,
+		),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrape_errors_total",
+			Help:      "Failed scrapes of the " + namespace + " by stage.",
+		}, []string{"stage"}),
+		missing: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "missing_attribute_total",
+			Help:      "Attributes of the " + namespace + " found absent or null while scraping.",
+		}, []string{"bean", "attribute"}),
+		restarts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "restarts_total",
+			Help:      "Restarts of the " + namespace + " seen between scrapes, detected by a new JVM start time or counters going backwards.",
+		}),
+	}
+	for _, stage := range []string{stageFetch, stageDecode, stageParse} {
+		h.errors.WithLabelValues(stage)
+	}
+	return h
+}
+
+// collect runs scrape and forwards the metrics it produced to ch only if it
+// succeeded, so a failed scrape exposes no stale or partial values.
+func (h *health) collect(ch chan<- prometheus.Metric, scrape func(ch chan<- prometheus.Metric) error) {
+	start := time.Now()
+	var metrics []prometheus.Metric
+	buf := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		for m := range buf {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+	err := scrape(buf)
+	close(buf)
+	<-done
+
+	up := 1.0
+	if err != nil {
+		log.Printf("error scraping %s: %v", h.namespace, err)
+		h.errors.WithLabelValues(errorStage(err)).Inc()
+		up = 0
+	} else {
+		for _, m := range metrics {
+			ch <- m
+		}
+	}
+	h.mtx.Lock()
+	if err == nil {
+		h.lastSuccessTime = start
+	}
+	lastSuccess := h.lastSuccessTime
+	h.mtx.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(h.up, prometheus.GaugeValue, up)
+	ch <- prometheus.MustNewConstMetric(h.duration, prometheus.GaugeValue, time.Since(start).Seconds())
+	if !lastSuccess.IsZero() {
+		ch <- prometheus.MustNewConstMetric(h.lastSuccess, prometheus.GaugeValue, float64(lastSuccess.UnixNano())/1e9)
+	}
+	h.errors.Collect(ch)
+	if h.missing != nil {
+		h.missing.Collect(ch)
+	}
+	h.restarts.Collect(ch)
+}
+
+// restart counts a restart of the daemon if countersReset, or if startTime
+// differs from the start time seen on the previous scrape. startTime is 0
+// when unknown.
+func (h *health) restart(countersReset bool, startTime float64) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if startTime != 0 {
+		if h.startTime != 0 && startTime != h.startTime {
+			countersReset = true
+		}
+		h.startTime = startTime
+	}
+	if countersReset {
+		h.restarts.Inc()
+	}
+}
+
+// jvmStartTime returns the StartTime of the java.lang:type=Runtime bean of
+// r, or 0 if r has none.
+func jvmStartTime(r *jmx.Response) float64 {
+	b, _ := r.Bean("java.lang:type=Runtime")
+	startTime, _ := b.Float("StartTime")
+	return startTime
+}
+
+// sendStateSet sends a 1 for state and a 0 for all other states, appending
+// the state to labelValues. A state missing from states is sent as well, so
+// new states of a later version are not lost.
+func sendStateSet(ch chan<- prometheus.Metric, desc *prometheus.Desc, states []string, state string, labelValues ...string) {
+	known := false
+	for _, s := range states {
+		v := 0.0
+		if s == state {
+			v, known = 1, true
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, append(labelValues, s)...)
+	}
+	if !known {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, append(labelValues, state)...)
+	}
+}
+
+// missingAttribute counts attr of b as missing.
+func (h *health) missingAttribute(b jmx.Bean, attr string) {
+	h.missing.WithLabelValues(b.Name(), attr).Inc()
+}
+
+// float returns the numeric attribute attr of b, counting it as missing if
+// b does not have it.
+func (h *health) float(b jmx.Bean, attr string) (float64, bool) {
+	v, ok := b.Float(attr)
+	if !ok {
+		h.missingAttribute(b, attr)
+	}
+	return v, ok
+}
+
+// string returns the string attribute attr of b, counting it as missing if
+// b does not have it.
+func (h *health) string(b jmx.Bean, attr string) (string, bool) {
+	v, ok := b.String(attr)
+	if !ok {
+		h.missingAttribute(b, attr)
+	}
+	return v, ok
+}
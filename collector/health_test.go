@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectFunc is an unchecked collector calling itself on Collect, to
+// gather the helpers that collectors are built of.
+type collectFunc func(ch chan<- prometheus.Metric)
+
+func (f collectFunc) Describe(ch chan<- *prometheus.Desc) {}
+
+func (f collectFunc) Collect(ch chan<- prometheus.Metric) { f(ch) }
+
+// gather collects c through a pedantic registry and returns the series of
+// the metric families named names, or of all families if none are named, as
+// sorted "name{label=value,...} value" lines.
+func gather(t *testing.T, c prometheus.Collector, names ...string) []string {
+	t.Helper()
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, mf := range families {
+		if len(names) > 0 && !contains(names, mf.GetName()) {
+			continue
+		}
+		for _, m := range mf.Metric {
+			var labels []string
+			for _, l := range m.Label {
+				labels = append(labels, l.GetName()+"="+l.GetValue())
+			}
+			v := m.GetGauge().GetValue() + m.GetCounter().GetValue() + m.GetUntyped().GetValue()
+			out = append(out, fmt.Sprintf("%s{%s} %g", mf.GetName(), strings.Join(labels, ","), v))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSeries(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSendStateSet(t *testing.T) {
+	desc := prometheus.NewDesc("test_state", "State.", []string{"node", "state"}, nil)
+	for _, tc := range []struct {
+		state string
+		want  []string
+	}{
+		{"b", []string{
+			"test_state{node=n1,state=a} 0",
+			"test_state{node=n1,state=b} 1",
+		}},
+		{"c", []string{
+			"test_state{node=n1,state=a} 0",
+			"test_state{node=n1,state=b} 0",
+			"test_state{node=n1,state=c} 1",
+		}},
+	} {
+		got := gather(t, collectFunc(func(ch chan<- prometheus.Metric) {
+			sendStateSet(ch, desc, []string{"a", "b"}, tc.state, "n1")
+		}))
+		checkSeries(t, got, tc.want...)
+	}
+}
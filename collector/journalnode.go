@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// journalBean is the name key property prefix of the bean a JournalNode
+// registers per journal, e.g. Journal-mycluster for the nameservice mycluster.
+const journalBean = "Journal-"
+
+// syncLatency matches the sync latency percentiles of a journal, e.g.
+// Syncs60s99thPercentileLatencyMicros.
+var syncLatency = regexp.MustCompile(`^Syncs(\d+s)(\d+)thPercentileLatencyMicros$`)
+
+// journalAttributes are the attributes of a Journal bean exported labeled by
+// nameservice.
+var journalAttributes = []struct {
+	attr, name, help string
+	valueType        prometheus.ValueType
+}{
+	{"CurrentLagTxns", "current_lag_txns", "Transactions the journal lags behind the writer.", prometheus.GaugeValue},
+	{"LastWrittenTxId", "last_written_txid", "Last transaction ID written to the journal.", prometheus.GaugeValue},
+	{"LastPromisedEpoch", "last_promised_epoch", "Last epoch promised to a writer.", prometheus.GaugeValue},
+	{"LastWriterEpoch", "last_writer_epoch", "Epoch of the last writer.", prometheus.GaugeValue},
+	{"BatchesWritten", "batches_written_total", "Batches of edits written to the journal.", prometheus.CounterValue},
+	{"BatchesWrittenWhileLagging", "batches_written_while_lagging_total", "Batches written while the journal was lagging.", prometheus.CounterValue},
+	{"TxnsWritten", "txns_written_total", "Transactions written to the journal.", prometheus.CounterValue},
+	{"BytesWritten", "bytes_written_total", "Bytes written to the journal.", prometheus.CounterValue},
+}
+
+// JournalNode turns the numeric attributes of the beans served by the
+// JournalNode /jmx servlet into metrics, see jmx.Mapper, and exports each
+// journal labeled by its nameservice.
+type JournalNode struct {
+	client  *fetch.Client
+	url     string
+	mapper  *jmx.Mapper
+	health  *health
+	rpc     *rpcMetrics
+	jvm     *jvmMetrics
+	journal *journalMetrics
+}
+
+// NewJournalNode returns a collector for the /jmx servlet at url.
+func NewJournalNode(client *fetch.Client, url string, rules *jmx.Config) *JournalNode {
+	return &JournalNode{
+		client:  client,
+		url:     url,
+		mapper:  jmx.NewMapper("journalnode", rules),
+		health:  newHealth("journalnode"),
+		rpc:     newRPCMetrics("journalnode"),
+		jvm:     newJVMMetrics("journalnode"),
+		journal: newJournalMetrics("journalnode"),
+	}
+}
+
+// Describe implements the prometheus.Collector interface. The metrics depend
+// on the beans the JournalNode serves, so none are described up front.
+func (c *JournalNode) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *JournalNode) Collect(ch chan<- prometheus.Metric) {
+	c.health.collect(ch, c.scrape)
+}
+
+func (c *JournalNode) scrape(ch chan<- prometheus.Metric) error {
+	var r jmx.Response
+	if err := c.client.JSON(c.url, &r); err != nil {
+		return err
+	}
+	if r.Beans == nil {
+		return parseError("%s has no beans", c.url)
+	}
+	reset := c.mapper.Collect(&r, ch)
+	c.health.restart(reset, jvmStartTime(&r))
+	c.jvm.collect(&r, c.health, ch)
+	c.rpc.collect(&r, c.health, ch)
+	for _, b := range r.Beans {
+		props := b.Properties()
+		if props["service"] == "JournalNode" && strings.HasPrefix(props["name"], journalBean) {
+			c.journal.collect(b, strings.TrimPrefix(props["name"], journalBean), c.health, ch)
+		}
+	}
+	return nil
+}
+
+// journalMetrics exports the journals of a JournalNode, labeled by their
+// nameservice.
+type journalMetrics struct {
+	attributes  []*prometheus.Desc
+	syncLatency *prometheus.Desc
+}
+
+func newJournalMetrics(namespace string) *journalMetrics {
+	m := &journalMetrics{
+		syncLatency: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "journal", "sync_latency_seconds"),
+			"Percentile of the latency of syncing edits to disk over the window.",
+			[]string{"nameservice", "window", "quantile"}, nil,
+		),
+	}
+	for _, a := range journalAttributes {
+		m.attributes = append(m.attributes, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "journal", a.name),
+			a.help,
+			[]string{"nameservice"}, nil,
+		))
+	}
+	return m
+}
+
+// collect exports the Journal bean b of nameservice.
+func (m *journalMetrics) collect(b jmx.Bean, nameservice string, h *health, ch chan<- prometheus.Metric) {
+	for i, a := range journalAttributes {
+		if v, ok := h.float(b, a.attr); ok {
+			ch <- prometheus.MustNewConstMetric(m.attributes[i], a.valueType, v, nameservice)
+		}
+	}
+	// The percentiles appear once the first window ended.
+	for attr := range b {
+		match := syncLatency.FindStringSubmatch(attr)
+		if match == nil {
+			continue
+		}
+		v, ok := b.Float(attr)
+		if !ok {
+			continue
+		}
+		p, _ := strconv.Atoi(match[2])
+		quantile := strconv.FormatFloat(float64(p)/100, 'f', -1, 64)
+		ch <- prometheus.MustNewConstMetric(m.syncLatency, prometheus.GaugeValue, v/1e6, nameservice, match[1], quantile)
+	}
+}
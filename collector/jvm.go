@@ -0,0 +1,204 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// usageFields are the fields of a java.lang.management.MemoryUsage.
+var usageFields = []string{"used", "committed", "max", "init"}
+
+// threadStates maps the thread counts of JvmMetrics to the state label.
+var threadStates = []struct{ attr, state string }{
+	{"ThreadsNew", "new"},
+	{"ThreadsRunnable", "runnable"},
+	{"ThreadsBlocked", "blocked"},
+	{"ThreadsWaiting", "waiting"},
+	{"ThreadsTimedWaiting", "timed_waiting"},
+	{"ThreadsTerminated", "terminated"},
+}
+
+// jvmMetrics exports the JVM a daemon runs in, whichever garbage collectors
+// and memory pools it was started with. It is shared by all roles.
+type jvmMetrics struct {
+	gcCollections    *prometheus.Desc
+	gcCollectionTime *prometheus.Desc
+	gcLastPause      *prometheus.Desc
+	metricsGCCount   *prometheus.Desc
+	metricsGCTime    *prometheus.Desc
+
+	// memory and pool hold a Desc per field of usageFields.
+	memory []*prometheus.Desc
+	pool   []*prometheus.Desc
+
+	threads         *prometheus.Desc
+	openFDs         *prometheus.Desc
+	maxFDs          *prometheus.Desc
+	classesLoaded   *prometheus.Desc
+	classesTotal    *prometheus.Desc
+	classesUnloaded *prometheus.Desc
+}
+
+func newJVMMetrics(namespace string) *jvmMetrics {
+	m := &jvmMetrics{
+		gcCollections: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_gc", "collections_total"),
+			"Collections run by the garbage collector.",
+			[]string{"gc"}, nil,
+		),
+		gcCollectionTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_gc", "collection_seconds_total"),
+			"Time spent in the garbage collector.",
+			[]string{"gc"}, nil,
+		),
+		gcLastPause: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_gc", "last_pause_seconds"),
+			"Duration of the garbage collector's last collection.",
+			[]string{"gc"}, nil,
+		),
+		metricsGCCount: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_metrics", "gc_count_total"),
+			"Collections run by the garbage collector, as counted by Hadoop's JvmMetrics.",
+			[]string{"gc"}, nil,
+		),
+		metricsGCTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_metrics", "gc_time_seconds_total"),
+			"Time spent in the garbage collector, as counted by Hadoop's JvmMetrics.",
+			[]string{"gc"}, nil,
+		),
+		threads: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm", "threads"),
+			"Threads of the JVM in the state, as counted by Hadoop's JvmMetrics.",
+			[]string{"state"}, nil,
+		),
+		openFDs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "process", "open_fds"),
+			"Open file descriptors of the process.",
+			nil, nil,
+		),
+		maxFDs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "process", "max_fds"),
+			"Maximum number of open file descriptors of the process.",
+			nil, nil,
+		),
+		classesLoaded: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm", "classes_loaded"),
+			"Classes currently loaded in the JVM.",
+			nil, nil,
+		),
+		classesTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm", "classes_loaded_total"),
+			"Classes loaded since the JVM started.",
+			nil, nil,
+		),
+		classesUnloaded: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm", "classes_unloaded_total"),
+			"Classes unloaded since the JVM started.",
+			nil, nil,
+		),
+	}
+	for _, f := range usageFields {
+		m.memory = append(m.memory, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_memory", "bytes_"+f),
+			"The "+f+" bytes of the JVM memory area.",
+			[]string{"area"}, nil,
+		))
+		m.pool = append(m.pool, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jvm_memory_pool", "bytes_"+f),
+			"The "+f+" bytes of the JVM memory pool.",
+			[]string{"pool"}, nil,
+		))
+	}
+	return m
+}
+
+func (m *jvmMetrics) collect(r *jmx.Response, h *health, ch chan<- prometheus.Metric) {
+	for _, b := range r.Beans {
+		domain, props := jmx.ParseObjectName(b.Name())
+		switch {
+		case domain == "java.lang" && props["type"] == "GarbageCollector":
+			m.collectGC(b, props["name"], h, ch)
+		case domain == "java.lang" && props["type"] == "Memory":
+			m.collectUsage(b, "HeapMemoryUsage", m.memory, "heap", h, ch)
+			m.collectUsage(b, "NonHeapMemoryUsage", m.memory, "nonheap", h, ch)
+		case domain == "java.lang" && props["type"] == "MemoryPool":
+			m.collectUsage(b, "Usage", m.pool, props["name"], h, ch)
+		case domain == "java.lang" && props["type"] == "OperatingSystem":
+			// Only Unix JVMs report file descriptors.
+			if v, ok := b.Float("OpenFileDescriptorCount"); ok {
+				ch <- prometheus.MustNewConstMetric(m.openFDs, prometheus.GaugeValue, v)
+			}
+			if v, ok := b.Float("MaxFileDescriptorCount"); ok {
+				ch <- prometheus.MustNewConstMetric(m.maxFDs, prometheus.GaugeValue, v)
+			}
+		case domain == "java.lang" && props["type"] == "ClassLoading":
+			if v, ok := h.float(b, "LoadedClassCount"); ok {
+				ch <- prometheus.MustNewConstMetric(m.classesLoaded, prometheus.GaugeValue, v)
+			}
+			if v, ok := h.float(b, "TotalLoadedClassCount"); ok {
+				ch <- prometheus.MustNewConstMetric(m.classesTotal, prometheus.CounterValue, v)
+			}
+			if v, ok := h.float(b, "UnloadedClassCount"); ok {
+				ch <- prometheus.MustNewConstMetric(m.classesUnloaded, prometheus.CounterValue, v)
+			}
+		case domain == "Hadoop" && props["name"] == "JvmMetrics":
+			m.collectJvmMetricsGC(b, ch)
+			for _, t := range threadStates {
+				if v, ok := h.float(b, t.attr); ok {
+					ch <- prometheus.MustNewConstMetric(m.threads, prometheus.GaugeValue, v, t.state)
+				}
+			}
+		}
+	}
+}
+
+// collectUsage exports the MemoryUsage attribute attr of b, e.g. the Usage of
+// the "G1 Old Gen" or "Metaspace" pool. A max of -1 means undefined.
+func (m *jvmMetrics) collectUsage(b jmx.Bean, attr string, descs []*prometheus.Desc, label string, h *health, ch chan<- prometheus.Metric) {
+	usage, ok := b.Object(attr)
+	if !ok {
+		h.missingAttribute(b, attr)
+		return
+	}
+	for i, f := range usageFields {
+		if v, ok := usage.Float(f); ok {
+			ch <- prometheus.MustNewConstMetric(descs[i], prometheus.GaugeValue, v, label)
+		}
+	}
+}
+
+// collectGC exports a java.lang:type=GarbageCollector bean, e.g. the
+// "G1 Young Generation", "ZGC Pauses" or "Shenandoah Cycles" collector.
+func (m *jvmMetrics) collectGC(b jmx.Bean, gc string, h *health, ch chan<- prometheus.Metric) {
+	if v, ok := h.float(b, "CollectionCount"); ok {
+		ch <- prometheus.MustNewConstMetric(m.gcCollections, prometheus.CounterValue, v, gc)
+	}
+	if v, ok := h.float(b, "CollectionTime"); ok {
+		ch <- prometheus.MustNewConstMetric(m.gcCollectionTime, prometheus.CounterValue, v/1000, gc)
+	}
+	// LastGcInfo is null until the collector first ran.
+	if info, ok := b.Object("LastGcInfo"); ok {
+		if v, ok := info.Float("duration"); ok {
+			ch <- prometheus.MustNewConstMetric(m.gcLastPause, prometheus.GaugeValue, v/1000, gc)
+		}
+	}
+}
+
+// collectJvmMetricsGC exports the GcCount<collector> and
+// GcTimeMillis<collector> attributes of the JvmMetrics bean.
+func (m *jvmMetrics) collectJvmMetricsGC(b jmx.Bean, ch chan<- prometheus.Metric) {
+	for attr := range b {
+		v, ok := b.Float(attr)
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(attr, "GcCount") && attr != "GcCount":
+			ch <- prometheus.MustNewConstMetric(m.metricsGCCount, prometheus.CounterValue, v, strings.TrimPrefix(attr, "GcCount"))
+		case strings.HasPrefix(attr, "GcTimeMillis") && attr != "GcTimeMillis":
+			ch <- prometheus.MustNewConstMetric(m.metricsGCTime, prometheus.CounterValue, v/1000, strings.TrimPrefix(attr, "GcTimeMillis"))
+		}
+	}
+}
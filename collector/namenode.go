@@ -0,0 +1,66 @@
+// Package collector implements the Prometheus collectors of the Hadoop roles.
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// NameNode turns the numeric attributes of the beans served by the NameNode
+// /jmx servlet into metrics, see jmx.Mapper, follows its HA state and startup
+// progress and exports the datanodes it knows of.
+type NameNode struct {
+	client    *fetch.Client
+	url       string
+	mapper    *jmx.Mapper
+	health    *health
+	ha        *haTracker
+	dataNodes *dataNodeMetrics
+	startup   *startupMetrics
+	rpc       *rpcMetrics
+	jvm       *jvmMetrics
+}
+
+// NewNameNode returns a collector for the /jmx servlet at url.
+func NewNameNode(client *fetch.Client, url string, rules *jmx.Config) *NameNode {
+	return &NameNode{
+		client:    client,
+		url:       url,
+		mapper:    jmx.NewMapper("namenode", rules),
+		health:    newHealth("namenode"),
+		rpc:       newRPCMetrics("namenode"),
+		jvm:       newJVMMetrics("namenode"),
+		ha:        newHATracker("namenode"),
+		dataNodes: newDataNodeMetrics("namenode"),
+		startup:   newStartupMetrics("namenode"),
+	}
+}
+
+// Describe implements the prometheus.Collector interface. The metrics depend
+// on the beans the NameNode serves, so none are described up front.
+func (c *NameNode) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *NameNode) Collect(ch chan<- prometheus.Metric) {
+	c.health.collect(ch, c.scrape)
+}
+
+func (c *NameNode) scrape(ch chan<- prometheus.Metric) error {
+	var r jmx.Response
+	if err := c.client.JSON(c.url, &r); err != nil {
+		return err
+	}
+	if r.Beans == nil {
+		return parseError("%s has no beans", c.url)
+	}
+	reset := c.mapper.Collect(&r, ch)
+	c.health.restart(reset, jvmStartTime(&r))
+	c.jvm.collect(&r, c.health, ch)
+	c.rpc.collect(&r, c.health, ch)
+	c.ha.collect(&r, c.health, ch)
+	c.dataNodes.collect(&r, c.health, ch)
+	c.startup.collect(&r, c.health, ch)
+	return nil
+}
@@ -0,0 +1,219 @@
+package collector
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+const nameNodeInfoBean = "Hadoop:service=NameNode,name=NameNodeInfo"
+
+// adminStates are the DatanodeInfo.AdminStates, as exported in the
+// admin_state label.
+var adminStates = []string{"in_service", "decommission_in_progress", "decommissioned", "entering_maintenance", "in_maintenance"}
+
+// liveNode is an entry of the NameNodeInfo LiveNodes attribute, a JSON
+// object keyed by datanode. Attributes an older Hadoop does not report stay
+// nil.
+type liveNode struct {
+	LastContact     *float64 `json:"lastContact"`
+	AdminState      string   `json:"adminState"`
+	Capacity        *float64 `json:"capacity"`
+	Used            *float64 `json:"usedSpace"`
+	NonDfsUsed      *float64 `json:"nonDfsUsedSpace"`
+	Remaining       *float64 `json:"remaining"`
+	BlockPoolUsed   *float64 `json:"blockPoolUsed"`
+	NumBlocks       *float64 `json:"numBlocks"`
+	VolumeFailures  *float64 `json:"volfails"`
+	BlocksScheduled *float64 `json:"blockScheduled"`
+}
+
+// deadNode is an entry of the NameNodeInfo DeadNodes attribute.
+type deadNode struct {
+	LastContact    *float64 `json:"lastContact"`
+	AdminState     string   `json:"adminState"`
+	Decommissioned bool     `json:"decommissioned"`
+}
+
+// decomNode is an entry of the NameNodeInfo DecomNodes attribute.
+type decomNode struct {
+	UnderReplicatedBlocks      *float64 `json:"underReplicatedBlocks"`
+	DecommissionOnlyReplicas   *float64 `json:"decommissionOnlyReplicas"`
+	UnderReplicatedInOpenFiles *float64 `json:"underReplicateInOpenFiles"`
+}
+
+// dataNodeMetrics exports the datanodes a NameNode knows of, labeled by
+// their host:port.
+type dataNodeMetrics struct {
+	live                       *prometheus.Desc
+	adminState                 *prometheus.Desc
+	lastContact                *prometheus.Desc
+	capacity                   *prometheus.Desc
+	used                       *prometheus.Desc
+	nonDfsUsed                 *prometheus.Desc
+	remaining                  *prometheus.Desc
+	blockPoolUsed              *prometheus.Desc
+	blocks                     *prometheus.Desc
+	blocksScheduled            *prometheus.Desc
+	volumeFailures             *prometheus.Desc
+	underReplicated            *prometheus.Desc
+	decommissionOnlyReplicas   *prometheus.Desc
+	underReplicatedInOpenFiles *prometheus.Desc
+}
+
+func newDataNodeMetrics(namespace string) *dataNodeMetrics {
+	return &dataNodeMetrics{
+		live: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "live"),
+			"Whether the NameNode counts the datanode as live.",
+			[]string{"datanode"}, nil,
+		),
+		adminState: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "admin_state"),
+			"Admin state of the datanode, 1 for the current state.",
+			[]string{"datanode", "state"}, nil,
+		),
+		lastContact: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "last_contact_seconds"),
+			"Seconds since the datanode's last heartbeat.",
+			[]string{"datanode"}, nil,
+		),
+		capacity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "capacity_bytes"),
+			"Configured capacity of the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		used: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "used_bytes"),
+			"DFS space used on the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		nonDfsUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "non_dfs_used_bytes"),
+			"Non-DFS space used on the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		remaining: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "remaining_bytes"),
+			"Space remaining for DFS on the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		blockPoolUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "block_pool_used_bytes"),
+			"Space used by the NameNode's block pool on the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		blocks: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "blocks"),
+			"Blocks stored on the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		blocksScheduled: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "blocks_scheduled"),
+			"Blocks scheduled to be written to the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		volumeFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "volume_failures"),
+			"Failed volumes of the datanode.",
+			[]string{"datanode"}, nil,
+		),
+		underReplicated: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "decommission_under_replicated_blocks"),
+			"Under-replicated blocks of a decommissioning datanode.",
+			[]string{"datanode"}, nil,
+		),
+		decommissionOnlyReplicas: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "decommission_only_replicas"),
+			"Blocks whose only replicas are on the decommissioning datanode.",
+			[]string{"datanode"}, nil,
+		),
+		underReplicatedInOpenFiles: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "datanode", "decommission_under_replicated_in_open_files"),
+			"Under-replicated blocks in open files of a decommissioning datanode.",
+			[]string{"datanode"}, nil,
+		),
+	}
+}
+
+// collect exports the datanodes listed in the NameNodeInfo bean of r, giving
+// a view of the whole fleet from a single NameNode.
+func (m *dataNodeMetrics) collect(r *jmx.Response, h *health, ch chan<- prometheus.Metric) {
+	b, ok := r.Bean(nameNodeInfoBean)
+	if !ok {
+		return
+	}
+	var live map[string]liveNode
+	if decodeNodes(b, "LiveNodes", &live, h) {
+		for dn, n := range live {
+			ch <- prometheus.MustNewConstMetric(m.live, prometheus.GaugeValue, 1, dn)
+			m.sendAdminState(ch, dn, n.AdminState)
+			sendNode(ch, m.lastContact, dn, n.LastContact)
+			sendNode(ch, m.capacity, dn, n.Capacity)
+			sendNode(ch, m.used, dn, n.Used)
+			sendNode(ch, m.nonDfsUsed, dn, n.NonDfsUsed)
+			sendNode(ch, m.remaining, dn, n.Remaining)
+			sendNode(ch, m.blockPoolUsed, dn, n.BlockPoolUsed)
+			sendNode(ch, m.blocks, dn, n.NumBlocks)
+			sendNode(ch, m.blocksScheduled, dn, n.BlocksScheduled)
+			sendNode(ch, m.volumeFailures, dn, n.VolumeFailures)
+		}
+	}
+	var dead map[string]deadNode
+	if decodeNodes(b, "DeadNodes", &dead, h) {
+		for dn, n := range dead {
+			if _, ok := live[dn]; ok {
+				continue
+			}
+			state := n.AdminState
+			if state == "" && n.Decommissioned {
+				state = "Decommissioned"
+			}
+			ch <- prometheus.MustNewConstMetric(m.live, prometheus.GaugeValue, 0, dn)
+			m.sendAdminState(ch, dn, state)
+			sendNode(ch, m.lastContact, dn, n.LastContact)
+		}
+	}
+	var decom map[string]decomNode
+	if decodeNodes(b, "DecomNodes", &decom, h) {
+		for dn, n := range decom {
+			sendNode(ch, m.underReplicated, dn, n.UnderReplicatedBlocks)
+			sendNode(ch, m.decommissionOnlyReplicas, dn, n.DecommissionOnlyReplicas)
+			sendNode(ch, m.underReplicatedInOpenFiles, dn, n.UnderReplicatedInOpenFiles)
+		}
+	}
+}
+
+// decodeNodes decodes the JSON encoded attribute attr of b into v. An
+// attribute that is absent or malformed is counted as missing.
+func decodeNodes(b jmx.Bean, attr string, v interface{}, h *health) bool {
+	s, ok := h.string(b, attr)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		log.Printf("error decoding %s of %s: %v", attr, b.Name(), err)
+		h.missingAttribute(b, attr)
+		return false
+	}
+	return true
+}
+
+func sendNode(ch chan<- prometheus.Metric, desc *prometheus.Desc, dn string, v *float64) {
+	if v != nil {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, *v, dn)
+	}
+}
+
+// sendAdminState exports the admin state of datanode dn as reported, e.g.
+// "Decommission In Progress".
+func (m *dataNodeMetrics) sendAdminState(ch chan<- prometheus.Metric, dn, state string) {
+	if state == "" {
+		return
+	}
+	state = strings.ToLower(strings.Replace(state, " ", "_", -1))
+	sendStateSet(ch, m.adminState, adminStates, state, dn)
+}
@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// gatherDataNodes returns what dataNodeMetrics makes of a NameNodeInfo
+// bean with attrs, along with the attributes counted as missing.
+func gatherDataNodes(t *testing.T, attrs jmx.Bean, names ...string) []string {
+	m := newDataNodeMetrics("namenode")
+	h := newHealth("namenode")
+	attrs["name"] = nameNodeInfoBean
+	r := &jmx.Response{Beans: []jmx.Bean{attrs}}
+	return gather(t, collectFunc(func(ch chan<- prometheus.Metric) {
+		m.collect(r, h, ch)
+		h.missing.Collect(ch)
+	}), names...)
+}
+
+func TestDataNodeMetrics(t *testing.T) {
+	got := gatherDataNodes(t, jmx.Bean{
+		"LiveNodes": `{
+			"dn1:50010": {"lastContact": 1, "adminState": "In Service", "capacity": 1000, "usedSpace": 300,
+				"nonDfsUsedSpace": 50, "remaining": 650, "blockPoolUsed": 300, "numBlocks": 12,
+				"volfails": 0, "blockScheduled": 2},
+			"dn2:50010": {"lastContact": 2, "adminState": "Decommission In Progress"}
+		}`,
+		"DeadNodes":  `{"dn3:50010": {"lastContact": 700, "decommissioned": true}}`,
+		"DecomNodes": `{"dn2:50010": {"underReplicatedBlocks": 5, "decommissionOnlyReplicas": 1, "underReplicateInOpenFiles": 0}}`,
+	}, "namenode_datanode_live", "namenode_datanode_last_contact_seconds", "namenode_datanode_capacity_bytes",
+		"namenode_datanode_blocks", "namenode_datanode_decommission_under_replicated_blocks",
+		"namenode_missing_attribute_total")
+	checkSeries(t, got,
+		"namenode_datanode_blocks{datanode=dn1:50010} 12",
+		"namenode_datanode_capacity_bytes{datanode=dn1:50010} 1000",
+		"namenode_datanode_decommission_under_replicated_blocks{datanode=dn2:50010} 5",
+		"namenode_datanode_last_contact_seconds{datanode=dn1:50010} 1",
+		"namenode_datanode_last_contact_seconds{datanode=dn2:50010} 2",
+		"namenode_datanode_last_contact_seconds{datanode=dn3:50010} 700",
+		"namenode_datanode_live{datanode=dn1:50010} 1",
+		"namenode_datanode_live{datanode=dn2:50010} 1",
+		"namenode_datanode_live{datanode=dn3:50010} 0",
+	)
+}
+
+func TestDataNodeAdminState(t *testing.T) {
+	got := gatherDataNodes(t, jmx.Bean{
+		"LiveNodes":  `{"dn1:50010": {"adminState": "Entering Maintenance"}}`,
+		"DeadNodes":  `{"dn3:50010": {"decommissioned": true}, "dn4:50010": {"adminState": "Retired"}}`,
+		"DecomNodes": `{}`,
+	}, "namenode_datanode_admin_state")
+	var want []string
+	for dn, state := range map[string]string{
+		"dn1:50010": "entering_maintenance",
+		"dn3:50010": "decommissioned",
+		"dn4:50010": "retired",
+	} {
+		for _, s := range adminStates {
+			v := "0"
+			if s == state {
+				v = "1"
+			}
+			want = append(want, "namenode_datanode_admin_state{datanode="+dn+",state="+s+"} "+v)
+		}
+	}
+	// A state of a later version is exported as well.
+	want = append(want, "namenode_datanode_admin_state{datanode=dn4:50010,state=retired} 1")
+	checkSeries(t, got, want...)
+}
+
+func TestDataNodeLiveAndDead(t *testing.T) {
+	// A datanode that re-registered shows up in both lists for a while; it
+	// is live and must not be sent twice.
+	got := gatherDataNodes(t, jmx.Bean{
+		"LiveNodes":  `{"dn1:50010": {"lastContact": 1, "adminState": "In Service"}}`,
+		"DeadNodes":  `{"dn1:50010": {"lastContact": 900, "decommissioned": false}}`,
+		"DecomNodes": `{}`,
+	}, "namenode_datanode_live", "namenode_datanode_last_contact_seconds")
+	checkSeries(t, got,
+		"namenode_datanode_last_contact_seconds{datanode=dn1:50010} 1",
+		"namenode_datanode_live{datanode=dn1:50010} 1",
+	)
+}
+
+func TestDataNodeMalformed(t *testing.T) {
+	got := gatherDataNodes(t, jmx.Bean{
+		"LiveNodes": `{"dn1:50010": {"lastContact": "soon"}}`,
+		"DeadNodes": `{"dn3:50010": {"lastContact": 700}}`,
+	}, "namenode_datanode_live", "namenode_missing_attribute_total")
+	checkSeries(t, got,
+		"namenode_datanode_live{datanode=dn3:50010} 0",
+		"namenode_missing_attribute_total{attribute=DecomNodes,bean="+nameNodeInfoBean+"} 1",
+		"namenode_missing_attribute_total{attribute=LiveNodes,bean="+nameNodeInfoBean+"} 1",
+	)
+}
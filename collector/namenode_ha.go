@@ -0,0 +1,237 @@
+package collector
+
+import (
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// haStates are the states of org.apache.hadoop.ha.HAServiceProtocol.HAServiceState
+// as the NameNode reports them.
+var haStates = []string{"initializing", "active", "standby", "observer", "stopping"}
+
+const (
+	nameNodeStatusBean = "Hadoop:service=NameNode,name=NameNodeStatus"
+	fsNamesystemBean   = "Hadoop:service=NameNode,name=FSNamesystem"
+)
+
+// haState returns the HA state of the NameNode serving r, read from
+// NameNodeStatus or, on versions without it, the tag.HAState of FSNamesystem.
+func haState(r *jmx.Response, h *health) (string, bool) {
+	if b, ok := r.Bean(nameNodeStatusBean); ok {
+		return h.string(b, "State")
+	}
+	if b, ok := r.Bean(fsNamesystemBean); ok {
+		return h.string(b, "tag.HAState")
+	}
+	return "", false
+}
+
+// haTracker follows the HA state of a NameNode across scrapes.
+type haTracker struct {
+	state          *prometheus.Desc
+	lastTransition *prometheus.Desc
+	failovers      prometheus.Counter
+
+	mtx       sync.Mutex
+	lastState string
+	// observed is when a transition was seen, for versions whose
+	// NameNodeStatus has no LastHATransitionTime.
+	observed time.Time
+}
+
+func newHATracker(namespace string) *haTracker {
+	return &haTracker{
+		state: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ha", "state"),
+			"HA state of the NameNode, 1 for the current state.",
+			[]string{"state"}, nil,
+		),
+		lastTransition: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "last_state_transition_timestamp_seconds"),
+			"Time of the NameNode's last HA state transition.",
+			nil, nil,
+		),
+		failovers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ha",
+			Name:      "failovers_total",
+			Help:      "HA state transitions of the NameNode seen between scrapes.",
+		}),
+	}
+}
+
+func (t *haTracker) collect(r *jmx.Response, h *health, ch chan<- prometheus.Metric) {
+	defer t.failovers.Collect(ch)
+	state, ok := haState(r, h)
+	if !ok {
+		return
+	}
+	t.mtx.Lock()
+	if t.lastState != "" && state != t.lastState {
+		t.failovers.Inc()
+		t.observed = time.Now()
+	}
+	t.lastState = state
+	observed := t.observed
+	t.mtx.Unlock()
+
+	sendStateSet(ch, t.state, haStates, state)
+	b, _ := r.Bean(nameNodeStatusBean)
+	if millis, ok := b.Float("LastHATransitionTime"); ok && millis > 0 {
+		ch <- prometheus.MustNewConstMetric(t.lastTransition, prometheus.GaugeValue, millis/1000)
+	} else if !observed.IsZero() {
+		ch <- prometheus.MustNewConstMetric(t.lastTransition, prometheus.GaugeValue, float64(observed.UnixNano())/1e9)
+	}
+}
+
+// NameService follows the HA states of all NameNodes of a nameservice,
+// reading only their NameNodeStatus beans.
+type NameService struct {
+	client *fetch.Client
+	urls   []string
+
+	up        *prometheus.Desc
+	state     *prometheus.Desc
+	active    *prometheus.Desc
+	healthy   *prometheus.Desc
+	failovers prometheus.Counter
+
+	mtx        sync.Mutex
+	lastActive string
+}
+
+// NewNameService returns a collector for the NameNodes whose /jmx servlets
+// are at urls.
+func NewNameService(client *fetch.Client, urls []string) *NameService {
+	const namespace = "namenode"
+	return &NameService{
+		client: client,
+		urls:   urls,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nameservice", "up"),
+			"Whether the last scrape of the NameNode succeeded.",
+			[]string{"namenode"}, nil,
+		),
+		state: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nameservice", "ha_state"),
+			"HA state of the NameNode, 1 for the current state.",
+			[]string{"namenode", "state"}, nil,
+		),
+		active: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nameservice", "active_namenodes"),
+			"Number of active NameNodes in the nameservice.",
+			nil, nil,
+		),
+		healthy: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nameservice", "healthy"),
+			"Whether exactly one NameNode of the nameservice is active.",
+			nil, nil,
+		),
+		failovers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "nameservice",
+			Name:      "failovers_total",
+			Help:      "Changes of the active NameNode of the nameservice seen between scrapes.",
+		}),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *NameService) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.state
+	ch <- c.active
+	ch <- c.healthy
+	c.failovers.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *NameService) Collect(ch chan<- prometheus.Metric) {
+	states := make([]string, len(c.urls))
+	var wg sync.WaitGroup
+	for i, u := range c.urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			states[i] = c.scrape(u)
+		}(i, u)
+	}
+	wg.Wait()
+
+	active, activeURL := 0, ""
+	for i, u := range c.urls {
+		namenode := hostPort(u)
+		if states[i] == "" {
+			ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0, namenode)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1, namenode)
+		sendStateSet(ch, c.state, haStates, states[i], namenode)
+		if states[i] == "active" {
+			active++
+			activeURL = u
+		}
+	}
+	healthy := 0.0
+	if active == 1 {
+		healthy = 1
+		c.mtx.Lock()
+		if c.lastActive != "" && c.lastActive != activeURL {
+			c.failovers.Inc()
+		}
+		c.lastActive = activeURL
+		c.mtx.Unlock()
+	}
+	ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(active))
+	ch <- prometheus.MustNewConstMetric(c.healthy, prometheus.GaugeValue, healthy)
+	c.failovers.Collect(ch)
+}
+
+// scrape returns the HA state of the NameNode at u, or "" if it could not be
+// read.
+func (c *NameService) scrape(u string) string {
+	for _, bean := range []string{nameNodeStatusBean, fsNamesystemBean} {
+		var r jmx.Response
+		if err := c.client.JSON(withQuery(u, bean), &r); err != nil {
+			log.Printf("error scraping %s: %v", u, err)
+			return ""
+		}
+		if len(r.Beans) == 0 {
+			continue
+		}
+		if state, ok := r.Beans[0].String("State"); ok {
+			return state
+		}
+		if state, ok := r.Beans[0].String("tag.HAState"); ok {
+			return state
+		}
+	}
+	return ""
+}
+
+// withQuery restricts the /jmx servlet at u to the beans matching qry.
+func withQuery(u, qry string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	q := parsed.Query()
+	q.Set("qry", qry)
+	parsed.RawQuery = q.Encode()
+	return parsed.String()
+}
+
+// hostPort returns the host:port of u, used to label the members of a
+// cluster.
+func hostPort(u string) string {
+	if parsed, err := url.Parse(u); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return u
+}
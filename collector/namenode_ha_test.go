@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+func nameNodeStatus(state string, lastTransition float64) *jmx.Response {
+	return &jmx.Response{Beans: []jmx.Bean{{
+		"name":                 nameNodeStatusBean,
+		"State":                state,
+		"LastHATransitionTime": lastTransition,
+	}}}
+}
+
+func TestHATracker(t *testing.T) {
+	tracker := newHATracker("namenode")
+	h := newHealth("namenode")
+	var r *jmx.Response
+	c := collectFunc(func(ch chan<- prometheus.Metric) { tracker.collect(r, h, ch) })
+
+	for _, tc := range []struct {
+		r         *jmx.Response
+		failovers float64
+	}{
+		{nameNodeStatus("standby", 0), 0},
+		{nameNodeStatus("standby", 0), 0},
+		{nameNodeStatus("active", 1500000000000), 1},
+		// A NameNode whose state cannot be read did not fail over.
+		{&jmx.Response{}, 1},
+		{nameNodeStatus("active", 1500000000000), 1},
+		// Versions without NameNodeStatus report tag.HAState.
+		{&jmx.Response{Beans: []jmx.Bean{{"name": fsNamesystemBean, "tag.HAState": "standby"}}}, 2},
+	} {
+		r = tc.r
+		checkSeries(t, gather(t, c, "namenode_ha_failovers_total"),
+			fmt.Sprintf("namenode_ha_failovers_total{} %g", tc.failovers))
+	}
+}
+
+func TestHATrackerState(t *testing.T) {
+	tracker := newHATracker("namenode")
+	r := nameNodeStatus("active", 1500000000000)
+	got := gather(t, collectFunc(func(ch chan<- prometheus.Metric) { tracker.collect(r, newHealth("namenode"), ch) }))
+	checkSeries(t, got,
+		"namenode_ha_failovers_total{} 0",
+		"namenode_ha_state{state=active} 1",
+		"namenode_ha_state{state=initializing} 0",
+		"namenode_ha_state{state=observer} 0",
+		"namenode_ha_state{state=standby} 0",
+		"namenode_ha_state{state=stopping} 0",
+		"namenode_last_state_transition_timestamp_seconds{} 1.5e+09",
+	)
+}
+
+// fakeNameNode serves a NameNodeStatus bean reporting the state it is set
+// to.
+type fakeNameNode struct {
+	*httptest.Server
+	mtx   sync.Mutex
+	state string
+}
+
+func newFakeNameNode(t *testing.T, state string) *fakeNameNode {
+	nn := &fakeNameNode{state: state}
+	nn.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("qry") != nameNodeStatusBean {
+			w.Write([]byte(`{"beans": []}`))
+			return
+		}
+		nn.mtx.Lock()
+		defer nn.mtx.Unlock()
+		fmt.Fprintf(w, `{"beans": [{"name": %q, "State": %q}]}`, nameNodeStatusBean, nn.state)
+	}))
+	t.Cleanup(nn.Close)
+	return nn
+}
+
+func (nn *fakeNameNode) set(state string) {
+	nn.mtx.Lock()
+	nn.state = state
+	nn.mtx.Unlock()
+}
+
+func TestNameService(t *testing.T) {
+	nn1 := newFakeNameNode(t, "active")
+	nn2 := newFakeNameNode(t, "standby")
+	client, err := fetch.NewClient(fetch.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewNameService(client, []string{nn1.URL + "/jmx", nn2.URL + "/jmx"})
+	host1 := strings.TrimPrefix(nn1.URL, "http://")
+
+	for _, tc := range []struct {
+		nn1, nn2                   string
+		active, healthy, failovers float64
+	}{
+		{"active", "standby", 1, 1, 0},
+		{"standby", "active", 1, 1, 1},
+		{"standby", "active", 1, 1, 1},
+		// No active NameNode is not a failover yet ...
+		{"standby", "standby", 0, 0, 1},
+		// ... but the next one becoming active is.
+		{"active", "standby", 1, 1, 2},
+		{"active", "active", 2, 0, 2},
+		{"active", "standby", 1, 1, 2},
+	} {
+		nn1.set(tc.nn1)
+		nn2.set(tc.nn2)
+		checkSeries(t, gather(t, c,
+			"namenode_nameservice_active_namenodes",
+			"namenode_nameservice_healthy",
+			"namenode_nameservice_failovers_total",
+		),
+			fmt.Sprintf("namenode_nameservice_active_namenodes{} %g", tc.active),
+			fmt.Sprintf("namenode_nameservice_healthy{} %g", tc.healthy),
+			fmt.Sprintf("namenode_nameservice_failovers_total{} %g", tc.failovers),
+		)
+	}
+
+	nn2.Close()
+	checkSeries(t, gather(t, c, "namenode_nameservice_up", "namenode_nameservice_healthy", "namenode_nameservice_failovers_total"),
+		"namenode_nameservice_failovers_total{} 2",
+		"namenode_nameservice_healthy{} 1",
+		"namenode_nameservice_up{namenode="+host1+"} 1",
+		"namenode_nameservice_up{namenode="+strings.TrimPrefix(nn2.URL, "http://")+"} 0",
+	)
+}
@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+const (
+	startupProgressBean   = "Hadoop:service=NameNode,name=StartupProgress"
+	fsNamesystemStateBean = "Hadoop:service=NameNode,name=FSNamesystemState"
+)
+
+// startupPhases maps the phases of StartupProgress, which prefix its
+// attributes, to the phase label.
+var startupPhases = []struct{ attr, phase string }{
+	{"LoadingFsImage", "loading_fsimage"},
+	{"LoadingEdits", "loading_edits"},
+	{"SavingCheckpoint", "saving_checkpoint"},
+	{"SafeMode", "safemode"},
+}
+
+// startupMetrics exports the StartupProgress of a NameNode and whether it is
+// in safe mode.
+type startupMetrics struct {
+	progress      *prometheus.Desc
+	elapsed       *prometheus.Desc
+	phaseProgress *prometheus.Desc
+	phaseElapsed  *prometheus.Desc
+	phaseCount    *prometheus.Desc
+	phaseExpected *prometheus.Desc
+	safemode      *prometheus.Desc
+}
+
+func newStartupMetrics(namespace string) *startupMetrics {
+	return &startupMetrics{
+		progress: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "startup", "progress"),
+			"Fraction of the NameNode startup completed.",
+			nil, nil,
+		),
+		elapsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "startup", "elapsed_seconds"),
+			"Time spent starting up the NameNode.",
+			nil, nil,
+		),
+		phaseProgress: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "startup", "phase_progress"),
+			"Fraction of the startup phase completed.",
+			[]string{"phase"}, nil,
+		),
+		phaseElapsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "startup", "phase_elapsed_seconds"),
+			"Time spent in the startup phase.",
+			[]string{"phase"}, nil,
+		),
+		phaseCount: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "startup", "phase_count"),
+			"Items processed by the startup phase, e.g. inodes loaded from the fsimage or edits applied.",
+			[]string{"phase"}, nil,
+		),
+		phaseExpected: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "startup", "phase_count_expected"),
+			"Items the startup phase expects to process.",
+			[]string{"phase"}, nil,
+		),
+		safemode: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "safemode", "active"),
+			"Whether the NameNode is in safe mode.",
+			nil, nil,
+		),
+	}
+}
+
+// collect exports how far the NameNode got loading its namespace and whether
+// it is still in safe mode.
+func (m *startupMetrics) collect(r *jmx.Response, h *health, ch chan<- prometheus.Metric) {
+	if b, ok := r.Bean(startupProgressBean); ok {
+		if v, ok := h.float(b, "PercentComplete"); ok {
+			ch <- prometheus.MustNewConstMetric(m.progress, prometheus.GaugeValue, v)
+		}
+		if v, ok := h.float(b, "ElapsedTime"); ok {
+			ch <- prometheus.MustNewConstMetric(m.elapsed, prometheus.GaugeValue, v/1000)
+		}
+		for _, p := range startupPhases {
+			if v, ok := h.float(b, p.attr+"PercentComplete"); ok {
+				ch <- prometheus.MustNewConstMetric(m.phaseProgress, prometheus.GaugeValue, v, p.phase)
+			}
+			if v, ok := h.float(b, p.attr+"ElapsedTime"); ok {
+				ch <- prometheus.MustNewConstMetric(m.phaseElapsed, prometheus.GaugeValue, v/1000, p.phase)
+			}
+			if v, ok := h.float(b, p.attr+"Count"); ok {
+				ch <- prometheus.MustNewConstMetric(m.phaseCount, prometheus.GaugeValue, v, p.phase)
+			}
+			if v, ok := h.float(b, p.attr+"Total"); ok {
+				ch <- prometheus.MustNewConstMetric(m.phaseExpected, prometheus.GaugeValue, v, p.phase)
+			}
+		}
+	}
+
+	// NameNodeInfo.Safemode is empty unless in safe mode, when it holds the
+	// status message of the web UI.
+	active, known := 0.0, false
+	if b, ok := r.Bean(nameNodeInfoBean); ok {
+		if s, ok := h.string(b, "Safemode"); ok {
+			known = true
+			if s != "" {
+				active = 1
+			}
+		}
+	} else if b, ok := r.Bean(fsNamesystemStateBean); ok {
+		if s, ok := h.string(b, "FSState"); ok {
+			known = true
+			if s == "safeMode" {
+				active = 1
+			}
+		}
+	}
+	if known {
+		ch <- prometheus.MustNewConstMetric(m.safemode, prometheus.GaugeValue, active)
+	}
+}
@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+const (
+	nodeManagerMetricsBean = "Hadoop:service=NodeManager,name=NodeManagerMetrics"
+	shuffleMetricsBean     = "Hadoop:service=NodeManager,name=ShuffleMetrics"
+	// containerResourceBean is the name key property prefix of the bean
+	// registered per container with yarn.nodemanager.container-metrics.enable,
+	// e.g. ContainerResource_container_1700000000000_0001_01_000002.
+	containerResourceBean = "ContainerResource_"
+)
+
+// nodeManagerAttributes are the attributes of the NodeManagerMetrics and
+// ShuffleMetrics beans exported under names of their own. factor turns
+// them into base units.
+var nodeManagerAttributes = []struct {
+	bean, attr, name, help string
+	factor                 float64
+	valueType              prometheus.ValueType
+}{
+	{nodeManagerMetricsBean, "ContainersLaunched", "containers_launched_total", "Containers launched.", 1, prometheus.CounterValue},
+	{nodeManagerMetricsBean, "ContainersCompleted", "containers_completed_total", "Containers completed.", 1, prometheus.CounterValue},
+	{nodeManagerMetricsBean, "ContainersFailed", "containers_failed_total", "Containers failed.", 1, prometheus.CounterValue},
+	{nodeManagerMetricsBean, "ContainersKilled", "containers_killed_total", "Containers killed.", 1, prometheus.CounterValue},
+	{nodeManagerMetricsBean, "ContainersIniting", "containers_initing", "Containers initializing.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "ContainersRunning", "containers_running", "Containers running.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "AllocatedContainers", "allocated_containers", "Containers allocated on the node.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "AllocatedGB", "allocated_memory_bytes", "Memory allocated to containers.", 1 << 30, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "AvailableGB", "available_memory_bytes", "Memory available for containers.", 1 << 30, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "AllocatedVCores", "allocated_vcores", "Virtual cores allocated to containers.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "AvailableVCores", "available_vcores", "Virtual cores available for containers.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "BadLocalDirs", "bad_local_dirs", "Local directories failing the disk health check.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "BadLogDirs", "bad_log_dirs", "Log directories failing the disk health check.", 1, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "GoodLocalDirsDiskUtilizationPerc", "good_local_dirs_disk_utilization_ratio", "Disk utilization of the healthy local directories.", 0.01, prometheus.GaugeValue},
+	{nodeManagerMetricsBean, "GoodLogDirsDiskUtilizationPerc", "good_log_dirs_disk_utilization_ratio", "Disk utilization of the healthy log directories.", 0.01, prometheus.GaugeValue},
+	{shuffleMetricsBean, "ShuffleOutputBytes", "shuffle_output_bytes_total", "Bytes served by the shuffle handler.", 1, prometheus.CounterValue},
+	{shuffleMetricsBean, "ShuffleOutputsOK", "shuffle_outputs_ok_total", "Map outputs served by the shuffle handler.", 1, prometheus.CounterValue},
+	{shuffleMetricsBean, "ShuffleOutputsFailed", "shuffle_outputs_failed_total", "Map outputs the shuffle handler failed to serve.", 1, prometheus.CounterValue},
+	{shuffleMetricsBean, "ShuffleConnections", "shuffle_connections", "Open connections of the shuffle handler.", 1, prometheus.GaugeValue},
+}
+
+// NodeManager turns the numeric attributes of the beans served by the
+// NodeManager /jmx servlet into metrics, see jmx.Mapper, and reads the node's
+// health and containers from its REST API.
+type NodeManager struct {
+	client *fetch.Client
+	url    string
+	mapper *jmx.Mapper
+	health *health
+	rpc    *rpcMetrics
+	jvm    *jvmMetrics
+
+	attributes       []*prometheus.Desc
+	healthy          *prometheus.Desc
+	healthUpdate     *prometheus.Desc
+	startTime        *prometheus.Desc
+	info             *prometheus.Desc
+	containers       *prometheus.Desc
+	containersMemory *prometheus.Desc
+	containersVCores *prometheus.Desc
+}
+
+// NewNodeManager returns a collector for the NodeManager web UI at url, e.g.
+// http://localhost:8042.
+func NewNodeManager(client *fetch.Client, url string, rules *jmx.Config) *NodeManager {
+	const namespace = "nodemanager"
+	c := &NodeManager{
+		client: client,
+		url:    url,
+		mapper: jmx.NewMapper(namespace, rules),
+		health: newHealth(namespace),
+		rpc:    newRPCMetrics(namespace),
+		jvm:    newJVMMetrics(namespace),
+		healthy: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "healthy"),
+			"Whether the node health checker reports the node healthy.",
+			nil, nil,
+		),
+		healthUpdate: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "health_update_timestamp_seconds"),
+			"Time the node health checker last ran.",
+			nil, nil,
+		),
+		startTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "start_time_seconds"),
+			"Time the NodeManager started.",
+			nil, nil,
+		),
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "info"),
+			"Versions of the NodeManager, always 1.",
+			[]string{"version", "hadoop_version"}, nil,
+		),
+		containers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "containers"),
+			"Containers on the node by state.",
+			[]string{"state"}, nil,
+		),
+		containersMemory: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "containers", "memory_needed_bytes"),
+			"Memory needed by the containers on the node by state.",
+			[]string{"state"}, nil,
+		),
+		containersVCores: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "containers", "vcores_needed"),
+			"Virtual cores needed by the containers on the node by state.",
+			[]string{"state"}, nil,
+		),
+	}
+	for _, a := range nodeManagerAttributes {
+		c.attributes = append(c.attributes, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", a.name),
+			a.help,
+			nil, nil,
+		))
+	}
+	return c
+}
+
+// Describe implements the prometheus.Collector interface. The metrics depend
+// on what the NodeManager serves, so none are described up front.
+func (c *NodeManager) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *NodeManager) Collect(ch chan<- prometheus.Metric) {
+	c.health.collect(ch, c.scrape)
+}
+
+func (c *NodeManager) scrape(ch chan<- prometheus.Metric) error {
+	url := c.url + "/jmx"
+	var r jmx.Response
+	if err := c.client.JSON(url, &r); err != nil {
+		return err
+	}
+	if r.Beans == nil {
+		return parseError("%s has no beans", url)
+	}
+	// The per-container beans come and go with the containers, exporting
+	// them would add series without bound.
+	beans := r.Beans[:0]
+	for _, b := range r.Beans {
+		if !strings.HasPrefix(b.Properties()["name"], containerResourceBean) {
+			beans = append(beans, b)
+		}
+	}
+	r.Beans = beans
+	reset := c.mapper.Collect(&r, ch)
+	c.health.restart(reset, jvmStartTime(&r))
+	c.jvm.collect(&r, c.health, ch)
+	c.rpc.collect(&r, c.health, ch)
+	for i, a := range nodeManagerAttributes {
+		b, ok := r.Bean(a.bean)
+		if !ok {
+			continue
+		}
+		if v, ok := c.health.float(b, a.attr); ok {
+			ch <- prometheus.MustNewConstMetric(c.attributes[i], a.valueType, v*a.factor)
+		}
+	}
+	if err := c.scrapeNodeInfo(ch); err != nil {
+		return err
+	}
+	return c.scrapeContainers(ch)
+}
+
+func (c *NodeManager) scrapeNodeInfo(ch chan<- prometheus.Metric) error {
+	url := c.url + "/ws/v1/node/info"
+	var m struct {
+		NodeInfo *struct {
+			NodeHealthy        bool    `json:"nodeHealthy"`
+			LastNodeUpdateTime float64 `json:"lastNodeUpdateTime"`
+			NMStartupTime      float64 `json:"nmStartupTime"`
+			NodeManagerVersion string  `json:"nodeManagerVersion"`
+			HadoopVersion      string  `json:"hadoopVersion"`
+		} `json:"nodeInfo"`
+	}
+	if err := c.client.JSON(url, &m); err != nil {
+		return err
+	}
+	info := m.NodeInfo
+	if info == nil {
+		return parseError("%s has no nodeInfo", url)
+	}
+	healthy := 0.0
+	if info.NodeHealthy {
+		healthy = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.healthy, prometheus.GaugeValue, healthy)
+	ch <- prometheus.MustNewConstMetric(c.healthUpdate, prometheus.GaugeValue, info.LastNodeUpdateTime/1000)
+	ch <- prometheus.MustNewConstMetric(c.startTime, prometheus.GaugeValue, info.NMStartupTime/1000)
+	ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, info.NodeManagerVersion, info.HadoopVersion)
+	return nil
+}
+
+func (c *NodeManager) scrapeContainers(ch chan<- prometheus.Metric) error {
+	url := c.url + "/ws/v1/node/containers"
+	// containers is null or {} on a node without containers, and holds a
+	// single container as an object rather than an array on some versions.
+	var m struct {
+		Containers interface{} `json:"containers"`
+	}
+	if err := c.client.JSON(url, &m); err != nil {
+		return err
+	}
+	type usage struct{ count, memory, vcores float64 }
+	states := map[string]*usage{}
+	for _, container := range list(m.Containers, "container") {
+		s, _ := container.String("state")
+		state := strings.ToLower(s)
+		u, ok := states[state]
+		if !ok {
+			u = &usage{}
+			states[state] = u
+		}
+		memory, _ := container.Float("totalMemoryNeededMB")
+		vcores, _ := container.Float("totalVCoresNeeded")
+		u.count++
+		u.memory += memory * (1 << 20)
+		u.vcores += vcores
+	}
+	for state, u := range states {
+		ch <- prometheus.MustNewConstMetric(c.containers, prometheus.GaugeValue, u.count, state)
+		ch <- prometheus.MustNewConstMetric(c.containersMemory, prometheus.GaugeValue, u.memory, state)
+		ch <- prometheus.MustNewConstMetric(c.containersVCores, prometheus.GaugeValue, u.vcores, state)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+)
+
+func TestNodeManagerContainers(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"array", `{"containers": {"container": [
+			{"state": "RUNNING", "totalMemoryNeededMB": 1024, "totalVCoresNeeded": 1},
+			{"state": "RUNNING", "totalMemoryNeededMB": 2048, "totalVCoresNeeded": 2},
+			{"state": "LOCALIZING", "totalMemoryNeededMB": 1024, "totalVCoresNeeded": 1}
+		]}}`, []string{
+			"nodemanager_containers{state=localizing} 1",
+			"nodemanager_containers{state=running} 2",
+			"nodemanager_containers_memory_needed_bytes{state=localizing} 1.073741824e+09",
+			"nodemanager_containers_memory_needed_bytes{state=running} 3.221225472e+09",
+			"nodemanager_containers_vcores_needed{state=localizing} 1",
+			"nodemanager_containers_vcores_needed{state=running} 3",
+		}},
+		{"single object", `{"containers": {"container":
+			{"state": "RUNNING", "totalMemoryNeededMB": 1024, "totalVCoresNeeded": 1}
+		}}`, []string{
+			"nodemanager_containers{state=running} 1",
+			"nodemanager_containers_memory_needed_bytes{state=running} 1.073741824e+09",
+			"nodemanager_containers_vcores_needed{state=running} 1",
+		}},
+		{"empty object", `{"containers": {}}`, nil},
+		{"null", `{"containers": null}`, nil},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(tc.body))
+		}))
+		client, err := fetch.NewClient(fetch.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewNodeManager(client, srv.URL, nil)
+		got := gather(t, collectFunc(func(ch chan<- prometheus.Metric) {
+			if err := c.scrapeContainers(ch); err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+		}))
+		srv.Close()
+		checkSeries(t, got, tc.want...)
+	}
+}
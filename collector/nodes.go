@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// nodeStates are the NodeStates of org.apache.hadoop.yarn.api.records, as
+// exported in the state label.
+var nodeStates = []string{"new", "running", "unhealthy", "decommissioning", "decommissioned", "lost", "rebooted", "shutdown"}
+
+// nodeAttributes are the numeric attributes of a node of
+// /ws/v1/cluster/nodes, scaled by factor into base units.
+var nodeAttributes = []struct {
+	attr, name, help string
+	factor           float64
+}{
+	{"numContainers", "containers", "Containers running on the node.", 1},
+	{"usedMemoryMB", "used_memory_bytes", "Memory allocated to containers on the node.", 1 << 20},
+	{"availMemoryMB", "available_memory_bytes", "Memory available for containers on the node.", 1 << 20},
+	{"usedVirtualCores", "used_vcores", "Virtual cores allocated to containers on the node.", 1},
+	{"availableVirtualCores", "available_vcores", "Virtual cores available for containers on the node.", 1},
+}
+
+// nodeMetrics exports the NodeManagers known to a ResourceManager, labeled
+// by their node id, so alerts can name the host.
+type nodeMetrics struct {
+	attributes   []*prometheus.Desc
+	state        *prometheus.Desc
+	healthReport *prometheus.Desc
+	healthAge    *prometheus.Desc
+	info         *prometheus.Desc
+	label        *prometheus.Desc
+}
+
+func newNodeMetrics(namespace string) *nodeMetrics {
+	m := &nodeMetrics{
+		state: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "state"),
+			"State of the node, 1 for the current state.",
+			[]string{"node", "state"}, nil,
+		),
+		healthReport: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "health_report"),
+			"Health report of a node whose health checker reports a problem, always 1.",
+			[]string{"node", "report"}, nil,
+		),
+		healthAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "health_update_age_seconds"),
+			"Time since the node last reported its health.",
+			[]string{"node"}, nil,
+		),
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "info"),
+			"Rack and version of the node, always 1.",
+			[]string{"node", "hostname", "rack", "version"}, nil,
+		),
+		label: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "label"),
+			"Node label of the node, always 1.",
+			[]string{"node", "label"}, nil,
+		),
+	}
+	for _, a := range nodeAttributes {
+		m.attributes = append(m.attributes, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", a.name),
+			a.help,
+			[]string{"node"}, nil,
+		))
+	}
+	return m
+}
+
+// collect exports the nodes of /ws/v1/cluster/nodes.
+func (m *nodeMetrics) collect(nodes []jmx.Bean, now time.Time, ch chan<- prometheus.Metric) {
+	for _, n := range nodes {
+		id, ok := n.String("id")
+		if !ok {
+			continue
+		}
+		if state, ok := n.String("state"); ok {
+			sendStateSet(ch, m.state, nodeStates, strings.ToLower(state), id)
+		}
+		if report, _ := n.String("healthReport"); report != "" {
+			ch <- prometheus.MustNewConstMetric(m.healthReport, prometheus.GaugeValue, 1, id, report)
+		}
+		if millis, ok := n.Float("lastHealthUpdate"); ok && millis > 0 {
+			age := now.Sub(time.Unix(0, int64(millis)*int64(time.Millisecond))).Seconds()
+			ch <- prometheus.MustNewConstMetric(m.healthAge, prometheus.GaugeValue, age, id)
+		}
+		hostname, _ := n.String("nodeHostName")
+		rack, _ := n.String("rack")
+		version, _ := n.String("version")
+		ch <- prometheus.MustNewConstMetric(m.info, prometheus.GaugeValue, 1, id, hostname, rack, version)
+		if labels, ok := n["nodeLabels"].([]interface{}); ok {
+			for _, l := range labels {
+				if l, ok := l.(string); ok {
+					ch <- prometheus.MustNewConstMetric(m.label, prometheus.GaugeValue, 1, id, l)
+				}
+			}
+		}
+		for i, a := range nodeAttributes {
+			if v, ok := n.Float(a.attr); ok {
+				ch <- prometheus.MustNewConstMetric(m.attributes[i], prometheus.GaugeValue, v*a.factor, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// ResourceManager turns the values of /ws/v1/cluster/metrics into metrics,
+// see jmx.Mapper. The clusterMetrics object is treated as a bean named
+// clusterMetricsBean, so rules match e.g.
+// "Hadoop:service=ResourceManager,name=ClusterMetricsInfo/appsCompleted".
+// The queues are read from /ws/v1/cluster/scheduler, the NodeManagers from
+// /ws/v1/cluster/nodes and the JVM from the java.lang and JvmMetrics beans of
+// /jmx.
+type ResourceManager struct {
+	client    *fetch.Client
+	url       string
+	mapper    *jmx.Mapper
+	health    *health
+	scheduler *schedulerMetrics
+	nodes     *nodeMetrics
+	// apps is nil unless per-application metrics are enabled.
+	apps *appMetrics
+	jvm  *jvmMetrics
+}
+
+// clusterMetricsBean names the clusterMetrics object of /ws/v1/cluster/metrics
+// after its ClusterMetricsInfo class. The JMX bean
+// Hadoop:service=ResourceManager,name=ClusterMetrics holds other attributes.
+const clusterMetricsBean = "Hadoop:service=ResourceManager,name=ClusterMetricsInfo"
+
+// NewResourceManager returns a collector for the ResourceManager REST API at
+// url, e.g. http://localhost:8088.
+func NewResourceManager(client *fetch.Client, url string, rules *jmx.Config, apps AppsConfig) *ResourceManager {
+	c := &ResourceManager{
+		client:    client,
+		url:       url,
+		mapper:    jmx.NewMapper("resourcemanager", rules),
+		health:    newHealth("resourcemanager"),
+		scheduler: newSchedulerMetrics("resourcemanager"),
+		nodes:     newNodeMetrics("resourcemanager"),
+		jvm:       newJVMMetrics("resourcemanager"),
+	}
+	if apps.TopN > 0 {
+		c.apps = newAppMetrics("resourcemanager", apps)
+	}
+	return c
+}
+
+// Describe implements the prometheus.Collector interface. The metrics depend
+// on what the ResourceManager serves, so none are described up front.
+func (c *ResourceManager) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ResourceManager) Collect(ch chan<- prometheus.Metric) {
+	c.health.collect(ch, c.scrape)
+}
+
+func (c *ResourceManager) scrape(ch chan<- prometheus.Metric) error {
+	url := c.url + "/ws/v1/cluster/metrics"
+	var m struct {
+		ClusterMetrics jmx.Bean `json:"clusterMetrics"`
+	}
+	if err := c.client.JSON(url, &m); err != nil {
+		return err
+	}
+	if m.ClusterMetrics == nil {
+		return parseError("%s has no clusterMetrics", url)
+	}
+	m.ClusterMetrics["name"] = clusterMetricsBean
+	reset := c.mapper.Collect(&jmx.Response{Beans: []jmx.Bean{m.ClusterMetrics}}, ch)
+
+	url = c.url + "/ws/v1/cluster/scheduler"
+	var s struct {
+		Scheduler struct {
+			SchedulerInfo jmx.Bean `json:"schedulerInfo"`
+		} `json:"scheduler"`
+	}
+	if err := c.client.JSON(url, &s); err != nil {
+		return err
+	}
+	if s.Scheduler.SchedulerInfo == nil {
+		return parseError("%s has no schedulerInfo", url)
+	}
+	c.scheduler.collect(s.Scheduler.SchedulerInfo, ch)
+
+	url = c.url + "/ws/v1/cluster/nodes"
+	var n struct {
+		Nodes interface{} `json:"nodes"`
+	}
+	if err := c.client.JSON(url, &n); err != nil {
+		return err
+	}
+	c.nodes.collect(list(n.Nodes, "node"), time.Now(), ch)
+
+	if c.apps != nil {
+		url = c.url + "/ws/v1/cluster/apps?states=RUNNING,ACCEPTED"
+		var a struct {
+			Apps interface{} `json:"apps"`
+		}
+		if err := c.client.JSON(url, &a); err != nil {
+			return err
+		}
+		c.apps.collect(list(a.Apps, "app"), ch)
+	}
+
+	// Only the JVM beans are fetched, the full /jmx of a ResourceManager
+	// holds every queue and user.
+	var jvm jmx.Response
+	for _, qry := range []string{"java.lang:*", "Hadoop:name=JvmMetrics,*"} {
+		var r jmx.Response
+		if err := c.client.JSON(withQuery(c.url+"/jmx", qry), &r); err != nil {
+			return err
+		}
+		jvm.Beans = append(jvm.Beans, r.Beans...)
+	}
+	c.jvm.collect(&jvm, c.health, ch)
+	c.health.restart(reset, jvmStartTime(&jvm))
+	return nil
+}
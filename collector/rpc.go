@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// rpcBean matches the name key property of the beans an RPC server registers
+// per port, e.g. RpcActivityForPort8020 for the client RPC and further ones for
+// the service RPC and lifeline ports.
+var rpcBean = regexp.MustCompile(`^Rpc(Detailed)?ActivityForPort(\d+)$`)
+
+// rpcAttributes are the attributes of the RpcActivityForPort beans, exported
+// labeled by port.
+var rpcAttributes = []struct {
+	attr, name, help string
+	valueType        prometheus.ValueType
+}{
+	{"ReceivedBytes", "received_bytes_total", "Bytes received by the RPC server.", prometheus.CounterValue},
+	{"SentBytes", "sent_bytes_total", "Bytes sent by the RPC server.", prometheus.CounterValue},
+	{"RpcQueueTimeNumOps", "queue_time_ops_total", "RPC calls whose queue time was measured.", prometheus.CounterValue},
+	{"RpcQueueTimeAvgTime", "queue_time_avg_milliseconds", "Average time RPC calls waited in the queue.", prometheus.GaugeValue},
+	{"RpcProcessingTimeNumOps", "processing_time_ops_total", "RPC calls whose processing time was measured.", prometheus.CounterValue},
+	{"RpcProcessingTimeAvgTime", "processing_time_avg_milliseconds", "Average time spent processing RPC calls.", prometheus.GaugeValue},
+	{"RpcAuthenticationFailures", "authentication_failures_total", "Failed RPC authentications.", prometheus.CounterValue},
+	{"RpcAuthorizationFailures", "authorization_failures_total", "Failed RPC authorizations.", prometheus.CounterValue},
+	{"NumOpenConnections", "open_connections", "Open connections of the RPC server.", prometheus.GaugeValue},
+	{"CallQueueLength", "call_queue_length", "Length of the RPC call queue.", prometheus.GaugeValue},
+}
+
+// rpcMetrics exports the RPC servers of a daemon, whichever ports they
+// listen on.
+type rpcMetrics struct {
+	server     []*prometheus.Desc
+	methodOps  *prometheus.Desc
+	methodTime *prometheus.Desc
+}
+
+func newRPCMetrics(namespace string) *rpcMetrics {
+	m := &rpcMetrics{
+		methodOps: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rpc_method", "calls_total"),
+			"RPC calls of the method.",
+			[]string{"port", "method"}, nil,
+		),
+		methodTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rpc_method", "avg_time_milliseconds"),
+			"Average processing time of the RPC method.",
+			[]string{"port", "method"}, nil,
+		),
+	}
+	for _, a := range rpcAttributes {
+		m.server = append(m.server, prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rpc", a.name),
+			a.help,
+			[]string{"port"}, nil,
+		))
+	}
+	return m
+}
+
+func (m *rpcMetrics) collect(r *jmx.Response, h *health, ch chan<- prometheus.Metric) {
+	for _, b := range r.Beans {
+		match := rpcBean.FindStringSubmatch(b.Properties()["name"])
+		if match == nil {
+			continue
+		}
+		if port := match[2]; match[1] == "" {
+			m.collectServer(b, port, h, ch)
+		} else {
+			m.collectMethods(b, port, ch)
+		}
+	}
+}
+
+func (m *rpcMetrics) collectServer(b jmx.Bean, port string, h *health, ch chan<- prometheus.Metric) {
+	for i, a := range rpcAttributes {
+		if v, ok := h.float(b, a.attr); ok {
+			ch <- prometheus.MustNewConstMetric(m.server[i], a.valueType, v, port)
+		}
+	}
+}
+
+// collectMethods exports the <Method>NumOps and <Method>AvgTime attributes of
+// a RpcDetailedActivityForPort bean. Methods appear once first called.
+func (m *rpcMetrics) collectMethods(b jmx.Bean, port string, ch chan<- prometheus.Metric) {
+	for attr := range b {
+		v, ok := b.Float(attr)
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(attr, "NumOps"):
+			ch <- prometheus.MustNewConstMetric(m.methodOps, prometheus.CounterValue, v, port, strings.TrimSuffix(attr, "NumOps"))
+		case strings.HasSuffix(attr, "AvgTime"):
+			ch <- prometheus.MustNewConstMetric(m.methodTime, prometheus.GaugeValue, v, port, strings.TrimSuffix(attr, "AvgTime"))
+		}
+	}
+}
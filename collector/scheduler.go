@@ -0,0 +1,198 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// queueAttributes are the numeric attributes of a queue of
+// /ws/v1/cluster/scheduler. The CapacityScheduler and FairScheduler report
+// different ones, those a queue lacks are skipped. factor turns percentages
+// into ratios.
+var queueAttributes = []struct {
+	attr, name, help string
+	factor           float64
+}{
+	{"capacity", "capacity_ratio", "Configured capacity of the queue as a share of its parent.", 0.01},
+	{"usedCapacity", "used_capacity_ratio", "Capacity used by the queue as a share of its configured capacity.", 0.01},
+	{"maxCapacity", "max_capacity_ratio", "Maximum capacity of the queue as a share of its parent.", 0.01},
+	{"absoluteCapacity", "absolute_capacity_ratio", "Configured capacity of the queue as a share of the cluster.", 0.01},
+	{"absoluteUsedCapacity", "absolute_used_capacity_ratio", "Capacity used by the queue as a share of the cluster.", 0.01},
+	{"absoluteMaxCapacity", "absolute_max_capacity_ratio", "Maximum capacity of the queue as a share of the cluster.", 0.01},
+	{"numApplications", "applications", "Applications in the queue.", 1},
+	{"numActiveApplications", "active_applications", "Active applications in the queue.", 1},
+	{"numPendingApplications", "pending_applications", "Pending applications in the queue.", 1},
+	{"numActiveApps", "active_applications", "Active applications in the queue.", 1},
+	{"numPendingApps", "pending_applications", "Pending applications in the queue.", 1},
+	{"numContainers", "containers", "Containers allocated to the queue.", 1},
+	{"maxApplications", "max_applications", "Maximum number of applications in the queue.", 1},
+	{"maxApps", "max_applications", "Maximum number of applications in the queue.", 1},
+	{"maxApplicationsPerUser", "max_applications_per_user", "Maximum number of applications of a user in the queue.", 1},
+	{"userLimit", "user_limit_ratio", "Minimum share of the queue guaranteed to each user.", 0.01},
+	{"userLimitFactor", "user_limit_factor", "Multiple of the queue capacity a single user may use.", 1},
+}
+
+// queueResources are the resource attributes of a queue, each exported as
+// memory and vcores.
+var queueResources = []struct{ attr, name string }{
+	{"resourcesUsed", "used"},
+	{"usedResources", "used"},
+	{"fairResources", "fair_share"},
+	{"steadyFairResources", "steady_fair_share"},
+	{"minResources", "min"},
+	{"maxResources", "max"},
+}
+
+// userAttributes are the numeric attributes of the users of a
+// CapacityScheduler leaf queue.
+var userAttributes = []struct{ attr, name, help string }{
+	{"numActiveApplications", "active_applications", "Active applications of the user in the queue."},
+	{"numPendingApplications", "pending_applications", "Pending applications of the user in the queue."},
+}
+
+// userResources are the resource attributes of a user of a queue.
+var userResources = []struct{ attr, name string }{
+	{"resourcesUsed", "used"},
+	{"userResourceLimit", "resource_limit"},
+}
+
+// schedulerMetrics exports the queues of the YARN scheduler, labeled by
+// their full path such as root.default.
+type schedulerMetrics struct {
+	attributes map[string]*prometheus.Desc
+	resources  map[string]*prometheus.Desc
+}
+
+func newSchedulerMetrics(namespace string) *schedulerMetrics {
+	m := &schedulerMetrics{
+		attributes: map[string]*prometheus.Desc{},
+		resources:  map[string]*prometheus.Desc{},
+	}
+	for _, a := range queueAttributes {
+		m.attributes["queue/"+a.attr] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "queue", a.name),
+			a.help,
+			[]string{"queue"}, nil,
+		)
+	}
+	for _, a := range userAttributes {
+		m.attributes["user/"+a.attr] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "queue_user", a.name),
+			a.help,
+			[]string{"queue", "user"}, nil,
+		)
+	}
+	for _, r := range queueResources {
+		m.newResource(namespace, "queue", r.name, []string{"queue"})
+	}
+	for _, r := range userResources {
+		m.newResource(namespace, "queue_user", r.name, []string{"queue", "user"})
+	}
+	return m
+}
+
+func (m *schedulerMetrics) newResource(namespace, subsystem, name string, labels []string) {
+	m.resources[subsystem+"/"+name+"/memory"] = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, name+"_memory_bytes"),
+		"The "+strings.Replace(name, "_", " ", -1)+" memory of the "+strings.Replace(subsystem, "_", " ", -1)+".",
+		labels, nil,
+	)
+	m.resources[subsystem+"/"+name+"/vCores"] = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, name+"_vcores"),
+		"The "+strings.Replace(name, "_", " ", -1)+" virtual cores of the "+strings.Replace(subsystem, "_", " ", -1)+".",
+		labels, nil,
+	)
+}
+
+// collect exports the queues of the schedulerInfo object. The FifoScheduler
+// has none.
+func (m *schedulerMetrics) collect(info jmx.Bean, ch chan<- prometheus.Metric) {
+	root := info
+	if q, ok := info.Object("rootQueue"); ok {
+		root = q
+	}
+	if name, ok := root.String("queueName"); ok {
+		m.collectQueue(root, name, ch)
+	}
+}
+
+func (m *schedulerMetrics) collectQueue(q jmx.Bean, path string, ch chan<- prometheus.Metric) {
+	for _, a := range queueAttributes {
+		if v, ok := q.Float(a.attr); ok {
+			ch <- prometheus.MustNewConstMetric(m.attributes["queue/"+a.attr], prometheus.GaugeValue, v*a.factor, path)
+		}
+	}
+	for _, r := range queueResources {
+		m.collectResource(q, r.attr, "queue/"+r.name, ch, path)
+	}
+	for _, u := range list(q["users"], "user") {
+		user, _ := u.String("username")
+		for _, a := range userAttributes {
+			if v, ok := u.Float(a.attr); ok {
+				ch <- prometheus.MustNewConstMetric(m.attributes["user/"+a.attr], prometheus.GaugeValue, v, path, user)
+			}
+		}
+		for _, r := range userResources {
+			m.collectResource(u, r.attr, "queue_user/"+r.name, ch, path, user)
+		}
+	}
+
+	// The CapacityScheduler nests queues under "queues" and names them
+	// relative to their parent, the FairScheduler under "childQueues" with
+	// their full path.
+	children := list(q["queues"], "queue")
+	children = append(children, list(q["childQueues"], "queue")...)
+	for _, child := range children {
+		name, ok := child.String("queueName")
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(name, path+".") {
+			name = path + "." + name
+		}
+		m.collectQueue(child, name, ch)
+	}
+}
+
+// collectResource exports the memory, given in MB, and vCores of the
+// resource attribute attr of b.
+func (m *schedulerMetrics) collectResource(b jmx.Bean, attr, key string, ch chan<- prometheus.Metric, labelValues ...string) {
+	r, ok := b.Object(attr)
+	if !ok {
+		return
+	}
+	if v, ok := r.Float("memory"); ok {
+		ch <- prometheus.MustNewConstMetric(m.resources[key+"/memory"], prometheus.GaugeValue, v*(1<<20), labelValues...)
+	}
+	if v, ok := r.Float("vCores"); ok {
+		ch <- prometheus.MustNewConstMetric(m.resources[key+"/vCores"], prometheus.GaugeValue, v, labelValues...)
+	}
+}
+
+// list returns the objects of a list in the YARN REST API, given either as
+// an array or as an object holding the array under key, e.g.
+// {"queue": [...]}. A single element may be given as the object itself; an
+// empty object is an empty list.
+func list(v interface{}, key string) []jmx.Bean {
+	switch v := v.(type) {
+	case []interface{}:
+		var beans []jmx.Bean
+		for _, e := range v {
+			if b, ok := e.(map[string]interface{}); ok {
+				beans = append(beans, jmx.Bean(b))
+			}
+		}
+		return beans
+	case map[string]interface{}:
+		if e, ok := v[key]; ok {
+			return list(e, key)
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return []jmx.Bean{jmx.Bean(v)}
+	}
+	return nil
+}
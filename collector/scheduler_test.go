@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+)
+
+// gatherScheduler returns what schedulerMetrics makes of the schedulerInfo
+// object info.
+func gatherScheduler(t *testing.T, info string, names ...string) []string {
+	var b jmx.Bean
+	if err := json.Unmarshal([]byte(info), &b); err != nil {
+		t.Fatal(err)
+	}
+	m := newSchedulerMetrics("resourcemanager")
+	return gather(t, collectFunc(func(ch chan<- prometheus.Metric) { m.collect(b, ch) }), names...)
+}
+
+func TestCapacityScheduler(t *testing.T) {
+	got := gatherScheduler(t, `{
+		"type": "capacityScheduler", "queueName": "root", "capacity": 100, "usedCapacity": 25,
+		"queues": {"queue": [
+			{"queueName": "default", "capacity": 60, "usedCapacity": 50, "numApplications": 2,
+				"resourcesUsed": {"memory": 2048, "vCores": 2},
+				"users": {"user": [
+					{"username": "alice", "numActiveApplications": 1, "numPendingApplications": 0,
+						"resourcesUsed": {"memory": 1024, "vCores": 1}, "userResourceLimit": {"memory": 4096, "vCores": 4}},
+					{"username": "bob", "numActiveApplications": 1, "numPendingApplications": 1,
+						"resourcesUsed": {"memory": 1024, "vCores": 1}}
+				]}},
+			{"queueName": "prod", "capacity": 40, "usedCapacity": 0,
+				"queues": {"queue": {"queueName": "etl", "capacity": 100, "usedCapacity": 0, "users": null}}}
+		]}
+	}`, "resourcemanager_queue_capacity_ratio", "resourcemanager_queue_used_memory_bytes",
+		"resourcemanager_queue_user_active_applications", "resourcemanager_queue_user_pending_applications",
+		"resourcemanager_queue_user_used_vcores", "resourcemanager_queue_user_resource_limit_memory_bytes")
+	checkSeries(t, got,
+		"resourcemanager_queue_capacity_ratio{queue=root} 1",
+		"resourcemanager_queue_capacity_ratio{queue=root.default} 0.6",
+		"resourcemanager_queue_capacity_ratio{queue=root.prod} 0.4",
+		"resourcemanager_queue_capacity_ratio{queue=root.prod.etl} 1",
+		"resourcemanager_queue_used_memory_bytes{queue=root.default} 2.147483648e+09",
+		"resourcemanager_queue_user_active_applications{queue=root.default,user=alice} 1",
+		"resourcemanager_queue_user_active_applications{queue=root.default,user=bob} 1",
+		"resourcemanager_queue_user_pending_applications{queue=root.default,user=alice} 0",
+		"resourcemanager_queue_user_pending_applications{queue=root.default,user=bob} 1",
+		"resourcemanager_queue_user_resource_limit_memory_bytes{queue=root.default,user=alice} 4.294967296e+09",
+		"resourcemanager_queue_user_used_vcores{queue=root.default,user=alice} 1",
+		"resourcemanager_queue_user_used_vcores{queue=root.default,user=bob} 1",
+	)
+}
+
+func TestFairScheduler(t *testing.T) {
+	got := gatherScheduler(t, `{
+		"type": "fairScheduler",
+		"rootQueue": {"queueName": "root", "maxApps": 100,
+			"fairResources": {"memory": 8192, "vCores": 8},
+			"childQueues": {"queue": [
+				{"queueName": "root.default", "numActiveApps": 2, "numPendingApps": 1,
+					"usedResources": {"memory": 1024, "vCores": 1}},
+				{"queueName": "root.prod", "numActiveApps": 0, "numPendingApps": 0,
+					"childQueues": [{"queueName": "root.prod.etl", "numActiveApps": 3}]}
+			]}}
+	}`, "resourcemanager_queue_active_applications", "resourcemanager_queue_pending_applications",
+		"resourcemanager_queue_max_applications", "resourcemanager_queue_used_vcores",
+		"resourcemanager_queue_fair_share_memory_bytes")
+	checkSeries(t, got,
+		"resourcemanager_queue_active_applications{queue=root.default} 2",
+		"resourcemanager_queue_active_applications{queue=root.prod} 0",
+		"resourcemanager_queue_active_applications{queue=root.prod.etl} 3",
+		"resourcemanager_queue_fair_share_memory_bytes{queue=root} 8.589934592e+09",
+		"resourcemanager_queue_max_applications{queue=root} 100",
+		"resourcemanager_queue_pending_applications{queue=root.default} 1",
+		"resourcemanager_queue_pending_applications{queue=root.prod} 0",
+		"resourcemanager_queue_used_vcores{queue=root.default} 1",
+	)
+}
+
+func TestFifoScheduler(t *testing.T) {
+	got := gatherScheduler(t, `{"type": "fifoScheduler", "capacity": 1, "numNodes": 3}`)
+	checkSeries(t, got)
+}
+
+func TestList(t *testing.T) {
+	a := map[string]interface{}{"queueName": "a"}
+	b := map[string]interface{}{"queueName": "b"}
+	for _, tc := range []struct {
+		name string
+		v    interface{}
+		want []jmx.Bean
+	}{
+		{"wrapped array", map[string]interface{}{"queue": []interface{}{a, b}}, []jmx.Bean{a, b}},
+		{"bare array", []interface{}{a, b}, []jmx.Bean{a, b}},
+		{"wrapped object", map[string]interface{}{"queue": a}, []jmx.Bean{a}},
+		{"bare object", a, []jmx.Bean{a}},
+		{"empty object", map[string]interface{}{}, nil},
+		{"null", nil, nil},
+		{"non-objects", []interface{}{"a", 1.0, a}, []jmx.Bean{a}},
+	} {
+		if got := list(tc.v, "queue"); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: list = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/jmx"
+	"github.com/rusonding/hadoop_exporter/zookeeper"
+)
+
+// zkStates are the values of zk_server_state.
+var zkStates = []string{"leader", "follower", "observer", "standalone", "read-only"}
+
+// zkCounters are the mntr keys that only grow while the server runs.
+var zkCounters = map[string]bool{
+	"zk_packets_received": true,
+	"zk_packets_sent":     true,
+}
+
+// zkRestartKeys are the mntr keys whose drop between scrapes means the
+// server restarted. zk_uptime is served from ZooKeeper 3.5 on.
+var zkRestartKeys = []string{"zk_uptime", "zk_packets_received"}
+
+// ZooKeeper runs mntr against a ZooKeeper server on every scrape. Every
+// numeric key is exported under its own name, so keys only a leader serves,
+// such as zk_followers and zk_synced_followers, appear on the leader only.
+type ZooKeeper struct {
+	client *zookeeper.Client
+	addr   string
+	health *health
+
+	mtx  sync.Mutex
+	last map[string]float64
+}
+
+// NewZooKeeper returns a collector for the ZooKeeper server at addr, e.g.
+// zk1:2181.
+func NewZooKeeper(client *zookeeper.Client, addr string) *ZooKeeper {
+	h := newHealth("zk")
+	// mntr answers with whatever keys the version has; none is missing.
+	h.missing = nil
+	return &ZooKeeper{
+		client: client,
+		addr:   addr,
+		health: h,
+		last:   map[string]float64{},
+	}
+}
+
+// Describe implements the prometheus.Collector interface. The metrics depend
+// on what the server answers, so none are described up front.
+func (c *ZooKeeper) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ZooKeeper) Collect(ch chan<- prometheus.Metric) {
+	c.health.collect(ch, c.scrape)
+}
+
+func (c *ZooKeeper) scrape(ch chan<- prometheus.Metric) error {
+	values, err := c.client.Mntr(c.addr)
+	if err != nil {
+		return err
+	}
+	sendMntr(ch, values, nil)
+	c.health.restart(c.restarted(values), 0)
+	return nil
+}
+
+// restarted reports whether one of the zkRestartKeys of values is lower than
+// on the previous scrape.
+func (c *ZooKeeper) restarted(values map[string]string) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	reset := false
+	for _, key := range zkRestartKeys {
+		v, err := strconv.ParseFloat(values[key], 64)
+		if err != nil {
+			continue
+		}
+		if last, ok := c.last[key]; ok && v < last {
+			reset = true
+		}
+		c.last[key] = v
+	}
+	return reset
+}
+
+// sendMntr exports the mntr output values, adding labels to every metric.
+func sendMntr(ch chan<- prometheus.Metric, values map[string]string, labels prometheus.Labels) {
+	for key, value := range values {
+		name := jmx.MetricName(key)
+		if !strings.HasPrefix(name, "zk_") {
+			name = "zk_" + name
+		}
+		if key == "zk_server_state" {
+			desc := prometheus.NewDesc(
+				"zk_server_state",
+				"Role of the server in the ensemble, 1 for the current one.",
+				[]string{"state"}, labels,
+			)
+			sendStateSet(ch, desc, zkStates, value)
+			continue
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			valueType := prometheus.GaugeValue
+			if zkCounters[key] {
+				valueType = prometheus.CounterValue
+			}
+			desc := prometheus.NewDesc(name, key+" of the mntr output.", nil, labels)
+			ch <- prometheus.MustNewConstMetric(desc, valueType, v)
+			continue
+		}
+		// Other strings, such as zk_version, become info metrics labeled
+		// with the value, e.g. zk_version_info{version="3.4.6-1569965, ..."}.
+		label := strings.TrimPrefix(name, "zk_")
+		desc := prometheus.NewDesc(name+"_info", key+" of the mntr output, always 1.", []string{label}, labels)
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, value)
+	}
+}
@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rusonding/hadoop_exporter/zookeeper"
+)
+
+// zkMember is what a member of an ensemble answered on one scrape.
+type zkMember struct {
+	values map[string]string
+	zxid   uint64
+	// hasZxid is false if srvr failed or gave no zxid.
+	hasZxid bool
+}
+
+// ZooKeeperEnsemble scrapes all members of a ZooKeeper ensemble
+// concurrently, exporting their mntr output labeled by server and whether
+// the ensemble as a whole has a leader and a quorum.
+type ZooKeeperEnsemble struct {
+	client *zookeeper.Client
+	addrs  []string
+
+	up            *prometheus.Desc
+	size          *prometheus.Desc
+	membersUp     *prometheus.Desc
+	leaders       *prometheus.Desc
+	quorumSize    *prometheus.Desc
+	healthy       *prometheus.Desc
+	outOfSync     *prometheus.Desc
+	maxZxidLag    *prometheus.Desc
+	memberZxidLag *prometheus.Desc
+}
+
+// NewZooKeeperEnsemble returns a collector for the ZooKeeper servers at
+// addrs, e.g. zk1:2181, zk2:2181 and zk3:2181.
+func NewZooKeeperEnsemble(client *zookeeper.Client, addrs []string) *ZooKeeperEnsemble {
+	return &ZooKeeperEnsemble{
+		client: client,
+		addrs:  addrs,
+		up: prometheus.NewDesc(
+			"zk_ensemble_member_up",
+			"Whether the last mntr of the member succeeded.",
+			[]string{"server"}, nil,
+		),
+		size: prometheus.NewDesc(
+			"zk_ensemble_size",
+			"Number of configured members of the ensemble.",
+			nil, nil,
+		),
+		membersUp: prometheus.NewDesc(
+			"zk_ensemble_members_up",
+			"Number of members of the ensemble answering mntr.",
+			nil, nil,
+		),
+		leaders: prometheus.NewDesc(
+			"zk_ensemble_leaders",
+			"Number of members of the ensemble reporting to be the leader.",
+			nil, nil,
+		),
+		quorumSize: prometheus.NewDesc(
+			"zk_ensemble_quorum_size",
+			"Number of voting members needed for a quorum. Members reporting to be observers do not vote.",
+			nil, nil,
+		),
+		healthy: prometheus.NewDesc(
+			"zk_ensemble_healthy",
+			"Whether exactly one member is the leader and a quorum of voting members answers.",
+			nil, nil,
+		),
+		outOfSync: prometheus.NewDesc(
+			"zk_ensemble_followers_out_of_sync",
+			"Voting members other than the leader that the leader does not count as synced followers.",
+			nil, nil,
+		),
+		maxZxidLag: prometheus.NewDesc(
+			"zk_ensemble_max_zxid_lag",
+			"Difference between the highest and the lowest last zxid of the members, in transactions while they share an epoch.",
+			nil, nil,
+		),
+		memberZxidLag: prometheus.NewDesc(
+			"zk_ensemble_member_zxid_lag",
+			"Difference between the highest last zxid of the ensemble and the member's.",
+			[]string{"server"}, nil,
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface. The mntr metrics
+// depend on what the members answer, so none are described up front.
+func (c *ZooKeeperEnsemble) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ZooKeeperEnsemble) Collect(ch chan<- prometheus.Metric) {
+	members := make([]*zkMember, len(c.addrs))
+	var wg sync.WaitGroup
+	for i, addr := range c.addrs {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			members[i] = c.scrape(addr)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	up, leaders, observers := 0, 0, 0
+	var leader *zkMember
+	var maxZxid, minZxid uint64
+	zxids := 0
+	for i, addr := range c.addrs {
+		m := members[i]
+		if m == nil {
+			ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0, addr)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1, addr)
+		sendMntr(ch, m.values, prometheus.Labels{"server": addr})
+		up++
+		switch m.values["zk_server_state"] {
+		case "leader":
+			leaders++
+			leader = m
+		case "observer":
+			observers++
+		}
+		if m.hasZxid {
+			if zxids == 0 || m.zxid > maxZxid {
+				maxZxid = m.zxid
+			}
+			if zxids == 0 || m.zxid < minZxid {
+				minZxid = m.zxid
+			}
+			zxids++
+		}
+	}
+
+	voters := len(c.addrs) - observers
+	quorum := voters/2 + 1
+	healthy := 0.0
+	if leaders == 1 && up-observers >= quorum {
+		healthy = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(len(c.addrs)))
+	ch <- prometheus.MustNewConstMetric(c.membersUp, prometheus.GaugeValue, float64(up))
+	ch <- prometheus.MustNewConstMetric(c.leaders, prometheus.GaugeValue, float64(leaders))
+	ch <- prometheus.MustNewConstMetric(c.quorumSize, prometheus.GaugeValue, float64(quorum))
+	ch <- prometheus.MustNewConstMetric(c.healthy, prometheus.GaugeValue, healthy)
+	if leaders == 1 {
+		if synced, err := strconv.ParseFloat(leader.values["zk_synced_followers"], 64); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.outOfSync, prometheus.GaugeValue, float64(voters-1)-synced)
+		}
+	}
+	if zxids > 0 {
+		ch <- prometheus.MustNewConstMetric(c.maxZxidLag, prometheus.GaugeValue, float64(maxZxid-minZxid))
+		for i, addr := range c.addrs {
+			if m := members[i]; m != nil && m.hasZxid {
+				ch <- prometheus.MustNewConstMetric(c.memberZxidLag, prometheus.GaugeValue, float64(maxZxid-m.zxid), addr)
+			}
+		}
+	}
+}
+
+// scrape returns what the member at addr answered, or nil if mntr failed.
+// The zxid is read from srvr, as mntr does not report it.
+func (c *ZooKeeperEnsemble) scrape(addr string) *zkMember {
+	values, err := c.client.Mntr(addr)
+	if err != nil {
+		log.Printf("error scraping %s: %v", addr, err)
+		return nil
+	}
+	m := &zkMember{values: values}
+	fields, err := c.client.Srvr(addr)
+	if err != nil {
+		log.Printf("error scraping %s: %v", addr, err)
+		return m
+	}
+	if s, ok := fields["Zxid"]; ok {
+		if m.zxid, err = zookeeper.Zxid(s); err == nil {
+			m.hasZxid = true
+		}
+	}
+	return m
+}
@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"fmt"
+	"testing"
+)
+
+func zkMntr(state string, extra string) string {
+	return "zk_version\t3.5.9\nzk_server_state\t" + state + "\n" + extra
+}
+
+func zkSrvr(state string, zxid uint64) string {
+	return fmt.Sprintf("Zookeeper version: 3.5.9\nZxid: %#x\nMode: %s\nNode count: 42\n", zxid, state)
+}
+
+var ensembleMetrics = []string{
+	"zk_ensemble_size",
+	"zk_ensemble_members_up",
+	"zk_ensemble_leaders",
+	"zk_ensemble_quorum_size",
+	"zk_ensemble_healthy",
+	"zk_ensemble_followers_out_of_sync",
+	"zk_ensemble_max_zxid_lag",
+}
+
+func TestZooKeeperEnsemble(t *testing.T) {
+	leader := newFakeZooKeeper(t, zkMntr("leader", "zk_synced_followers\t2\n"), zkSrvr("leader", 0x100000010))
+	follower1 := newFakeZooKeeper(t, zkMntr("follower", ""), zkSrvr("follower", 0x100000010))
+	follower2 := newFakeZooKeeper(t, zkMntr("follower", ""), zkSrvr("follower", 0x10000000c))
+	observer := newFakeZooKeeper(t, zkMntr("observer", ""), zkSrvr("observer", 0x100000008))
+	c := NewZooKeeperEnsemble(zkClient, []string{leader.addr(), follower1.addr(), follower2.addr(), observer.addr()})
+
+	// The observer does not vote: three voters need two for a quorum.
+	checkSeries(t, gather(t, c, ensembleMetrics...),
+		"zk_ensemble_followers_out_of_sync{} 0",
+		"zk_ensemble_healthy{} 1",
+		"zk_ensemble_leaders{} 1",
+		"zk_ensemble_max_zxid_lag{} 8",
+		"zk_ensemble_members_up{} 4",
+		"zk_ensemble_quorum_size{} 2",
+		"zk_ensemble_size{} 4",
+	)
+	checkSeries(t, gather(t, c, "zk_ensemble_member_zxid_lag"),
+		"zk_ensemble_member_zxid_lag{server="+leader.addr()+"} 0",
+		"zk_ensemble_member_zxid_lag{server="+follower1.addr()+"} 0",
+		"zk_ensemble_member_zxid_lag{server="+follower2.addr()+"} 4",
+		"zk_ensemble_member_zxid_lag{server="+observer.addr()+"} 8",
+	)
+	checkSeries(t, gather(t, c, "zk_server_state"), func() []string {
+		var want []string
+		for server, state := range map[string]string{
+			leader.addr(): "leader", follower1.addr(): "follower",
+			follower2.addr(): "follower", observer.addr(): "observer",
+		} {
+			for _, s := range zkStates {
+				v := 0
+				if s == state {
+					v = 1
+				}
+				want = append(want, fmt.Sprintf("zk_server_state{server=%s,state=%s} %d", server, s, v))
+			}
+		}
+		return want
+	}()...)
+
+	// A follower the leader lost is out of sync, the quorum still holds.
+	leader.set(zkMntr("leader", "zk_synced_followers\t1\n"), zkSrvr("leader", 0x100000010))
+	follower2.stop()
+	checkSeries(t, gather(t, c, append(ensembleMetrics, "zk_ensemble_member_up")...),
+		"zk_ensemble_followers_out_of_sync{} 1",
+		"zk_ensemble_healthy{} 1",
+		"zk_ensemble_leaders{} 1",
+		"zk_ensemble_max_zxid_lag{} 8",
+		"zk_ensemble_member_up{server="+follower1.addr()+"} 1",
+		"zk_ensemble_member_up{server="+follower2.addr()+"} 0",
+		"zk_ensemble_member_up{server="+leader.addr()+"} 1",
+		"zk_ensemble_member_up{server="+observer.addr()+"} 1",
+		"zk_ensemble_members_up{} 3",
+		"zk_ensemble_quorum_size{} 2",
+		"zk_ensemble_size{} 4",
+	)
+
+	// Without the leader the quorum is lost.
+	leader.stop()
+	checkSeries(t, gather(t, c, "zk_ensemble_healthy", "zk_ensemble_leaders", "zk_ensemble_members_up"),
+		"zk_ensemble_healthy{} 0",
+		"zk_ensemble_leaders{} 0",
+		"zk_ensemble_members_up{} 2",
+	)
+}
+
+func TestZooKeeperEnsembleSplitBrain(t *testing.T) {
+	zk1 := newFakeZooKeeper(t, zkMntr("leader", "zk_synced_followers\t0\n"), zkSrvr("leader", 0x200000001))
+	zk2 := newFakeZooKeeper(t, zkMntr("leader", "zk_synced_followers\t0\n"), zkSrvr("leader", 0x200000003))
+	zk3 := newFakeZooKeeper(t, zkMntr("follower", ""), "")
+	c := NewZooKeeperEnsemble(zkClient, []string{zk1.addr(), zk2.addr(), zk3.addr()})
+
+	// Two leaders are unhealthy, and out of sync followers are unknown. A
+	// member whose srvr gives no zxid has no lag.
+	checkSeries(t, gather(t, c, append(ensembleMetrics, "zk_ensemble_member_zxid_lag")...),
+		"zk_ensemble_healthy{} 0",
+		"zk_ensemble_leaders{} 2",
+		"zk_ensemble_max_zxid_lag{} 2",
+		"zk_ensemble_member_zxid_lag{server="+zk1.addr()+"} 2",
+		"zk_ensemble_member_zxid_lag{server="+zk2.addr()+"} 0",
+		"zk_ensemble_members_up{} 3",
+		"zk_ensemble_quorum_size{} 2",
+		"zk_ensemble_size{} 3",
+	)
+}
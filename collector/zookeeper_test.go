@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rusonding/hadoop_exporter/zookeeper"
+)
+
+// fakeZooKeeper answers mntr and srvr with what it is set to, closing the
+// connection after each answer as a ZooKeeper server does.
+type fakeZooKeeper struct {
+	l          net.Listener
+	mtx        sync.Mutex
+	mntr, srvr string
+}
+
+func newFakeZooKeeper(t *testing.T, mntr, srvr string) *fakeZooKeeper {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zk := &fakeZooKeeper{l: l, mntr: mntr, srvr: srvr}
+	t.Cleanup(zk.stop)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go zk.answer(conn)
+		}
+	}()
+	return zk
+}
+
+func (zk *fakeZooKeeper) answer(conn net.Conn) {
+	defer conn.Close()
+	cmd := make([]byte, 4)
+	if _, err := io.ReadFull(conn, cmd); err != nil {
+		return
+	}
+	zk.mtx.Lock()
+	defer zk.mtx.Unlock()
+	switch string(cmd) {
+	case "mntr":
+		io.WriteString(conn, zk.mntr)
+	case "srvr":
+		io.WriteString(conn, zk.srvr)
+	}
+}
+
+func (zk *fakeZooKeeper) set(mntr, srvr string) {
+	zk.mtx.Lock()
+	zk.mntr, zk.srvr = mntr, srvr
+	zk.mtx.Unlock()
+}
+
+func (zk *fakeZooKeeper) addr() string {
+	return zk.l.Addr().String()
+}
+
+func (zk *fakeZooKeeper) stop() {
+	zk.l.Close()
+}
+
+var zkClient = &zookeeper.Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+func TestZooKeeper(t *testing.T) {
+	zk := newFakeZooKeeper(t, "zk_version\t3.4.6-1569965, built on 02/20/2014 09:09 GMT\n"+
+		"zk_server_state\tfollower\n"+
+		"zk_packets_received\t100\n"+
+		"zk_znode_count\t42\n", "")
+	c := NewZooKeeper(zkClient, zk.addr())
+	got := gather(t, c, "zk_up", "zk_packets_received", "zk_znode_count", "zk_server_state",
+		"zk_version_info", "zk_restarts_total", "zk_missing_attribute_total")
+	checkSeries(t, got,
+		"zk_packets_received{} 100",
+		"zk_restarts_total{} 0",
+		"zk_server_state{state=follower} 1",
+		"zk_server_state{state=leader} 0",
+		"zk_server_state{state=observer} 0",
+		"zk_server_state{state=read-only} 0",
+		"zk_server_state{state=standalone} 0",
+		"zk_up{} 1",
+		"zk_version_info{version=3.4.6-1569965, built on 02/20/2014 09:09 GMT} 1",
+		"zk_znode_count{} 42",
+	)
+}
+
+func TestZooKeeperRestarts(t *testing.T) {
+	zk := newFakeZooKeeper(t, "", "")
+	c := NewZooKeeper(zkClient, zk.addr())
+	for _, tc := range []struct {
+		mntr     string
+		restarts string
+	}{
+		{"zk_uptime\t5000\nzk_packets_received\t100\n", "zk_restarts_total{} 0"},
+		{"zk_uptime\t20000\nzk_packets_received\t150\n", "zk_restarts_total{} 0"},
+		{"zk_uptime\t1000\nzk_packets_received\t10\n", "zk_restarts_total{} 1"},
+		// 3.4 has no zk_uptime.
+		{"zk_packets_received\t50\n", "zk_restarts_total{} 1"},
+		{"zk_packets_received\t5\n", "zk_restarts_total{} 2"},
+		{"zk_uptime\t2000\nzk_packets_received\t20\n", "zk_restarts_total{} 2"},
+	} {
+		zk.set(tc.mntr, "")
+		checkSeries(t, gather(t, c, "zk_restarts_total"), tc.restarts)
+	}
+}
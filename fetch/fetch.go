@@ -0,0 +1,114 @@
+// Package fetch retrieves the JSON documents served by the web UIs of Hadoop
+// daemons, such as /jmx and the YARN REST API.
+package fetch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+)
+
+// Config configures how a Client connects and authenticates to the Hadoop
+// web UIs.
+type Config struct {
+	// TLSCAFile holds the PEM encoded CAs verifying HTTPS web UIs instead of
+	// the system roots.
+	TLSCAFile string
+	// TLSCertFile and TLSKeyFile hold a PEM encoded client certificate.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSServerName overrides the host name verified against the certificate.
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+
+	// KerberosPrincipal, e.g. "prometheus/host@EXAMPLE.COM", enables SPNEGO
+	// authentication with the keys in KerberosKeytab.
+	KerberosPrincipal string
+	KerberosKeytab    string
+	// Krb5Conf is the krb5.conf locating the KDC of the principal's realm.
+	Krb5Conf string
+
+	// Timeout bounds each request including reading the response body, so
+	// a daemon stalled in a long GC fails the scrape instead of blocking it.
+	// Zero means no timeout.
+	Timeout time.Duration
+}
+
+// Client fetches JSON documents over HTTP.
+type Client struct {
+	client *http.Client
+	// spnego is nil unless a Kerberos principal is configured.
+	spnego spnegoAuth
+}
+
+// NewClient returns a Client configured by cfg.
+func NewClient(cfg Config) (*Client, error) {
+	// Hadoop hands out a hadoop.auth cookie once SPNEGO succeeded, sparing
+	// a negotiation on every scrape.
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c := &Client{client: &http.Client{Transport: transport, Jar: jar, Timeout: cfg.Timeout}}
+	if cfg.KerberosPrincipal != "" {
+		cl, err := newKerberosClient(cfg.KerberosPrincipal, cfg.KerberosKeytab, cfg.Krb5Conf)
+		if err != nil {
+			return nil, err
+		}
+		c.spnego = kerberosAuth{cl}
+	}
+	return c, nil
+}
+
+// DecodeError is returned by JSON when the response body could not be decoded.
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s: %v", e.URL, e.Err)
+}
+
+// JSON fetches url and decodes the response body into v.
+func (c *Client) JSON(url string, v interface{}) error {
+	resp, err := c.get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return &DecodeError{URL: url, Err: err}
+	}
+	return nil
+}
+
+func (c *Client) get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil || c.spnego == nil || !negotiate(resp) {
+		return resp, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if err := c.spnego.setHeader(req); err != nil {
+		return nil, fmt.Errorf("SPNEGO for %s: %v", url, err)
+	}
+	return c.client.Do(req)
+}
@@ -0,0 +1,121 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSPNEGO stands in for a logged in Kerberos client.
+type fakeSPNEGO struct {
+	calls int
+}
+
+func (f *fakeSPNEGO) setHeader(req *http.Request) error {
+	f.calls++
+	req.Header.Set("Authorization", "Negotiate dG9rZW4=")
+	return nil
+}
+
+// kerberizedServer behaves like a Hadoop web UI with
+// hadoop.http.authentication.type=kerberos: it asks for SPNEGO, accepts the
+// fake token and hands out a hadoop.auth cookie honored on later requests.
+func kerberizedServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("hadoop.auth"); err == nil {
+			w.Write([]byte(`{"beans": []}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Negotiate dG9rZW4=" {
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "hadoop.auth", Value: "u=prometheus", Path: "/"})
+		w.Write([]byte(`{"beans": []}`))
+	}))
+}
+
+func newTestClient(t *testing.T, auth spnegoAuth) *Client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{client: &http.Client{Jar: jar}, spnego: auth}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		header string
+		want   bool
+	}{
+		{http.StatusUnauthorized, "Negotiate", true},
+		{http.StatusUnauthorized, "Negotiate oYGXMIGUoAMKAQE=", true},
+		{http.StatusUnauthorized, `Basic realm="hadoop"`, false},
+		{http.StatusUnauthorized, "", false},
+		{http.StatusOK, "Negotiate", false},
+	} {
+		resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+		if tc.header != "" {
+			resp.Header.Set("WWW-Authenticate", tc.header)
+		}
+		if got := negotiate(resp); got != tc.want {
+			t.Errorf("negotiate(%d %q) = %v, want %v", tc.status, tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestSPNEGOReusesCookie(t *testing.T) {
+	srv := kerberizedServer()
+	defer srv.Close()
+	auth := &fakeSPNEGO{}
+	c := newTestClient(t, auth)
+
+	for i := 0; i < 3; i++ {
+		var v struct{ Beans []interface{} }
+		if err := c.JSON(srv.URL+"/jmx", &v); err != nil {
+			t.Fatalf("scrape %d: %v", i, err)
+		}
+	}
+	if auth.calls != 1 {
+		t.Errorf("negotiated %d times, want once and then the hadoop.auth cookie", auth.calls)
+	}
+}
+
+func TestWithoutKerberos(t *testing.T) {
+	srv := kerberizedServer()
+	defer srv.Close()
+	c := newTestClient(t, nil)
+
+	var v struct{ Beans []interface{} }
+	err := c.JSON(srv.URL+"/jmx", &v)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("got error %v, want HTTP status 401", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	stalled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stalled
+	}))
+	defer srv.Close()
+	defer close(stalled)
+	c, err := NewClient(Config{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v struct{ Beans []interface{} }
+	start := time.Now()
+	if err := c.JSON(srv.URL+"/jmx", &v); err == nil {
+		t.Fatal("got no error from a stalled server")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("gave up after %v, want about 50ms", elapsed)
+	}
+}
@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	krb5client "github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// newKerberosClient logs principal in with the keys of keytab. The client
+// renews its ticket in the background and logs in again once the ticket can
+// no longer be renewed.
+func newKerberosClient(principal, keytabFile, krb5Conf string) (*krb5client.Client, error) {
+	i := strings.LastIndex(principal, "@")
+	if i < 0 {
+		return nil, fmt.Errorf("kerberos principal %q has no realm", principal)
+	}
+	kt, err := keytab.Load(keytabFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading keytab %s: %v", keytabFile, err)
+	}
+	conf, err := config.Load(krb5Conf)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %v", krb5Conf, err)
+	}
+	cl := krb5client.NewWithKeytab(principal[:i], principal[i+1:], kt, conf, krb5client.DisablePAFXFAST(true))
+	if err := cl.Login(); err != nil {
+		return nil, fmt.Errorf("kerberos login as %s: %v", principal, err)
+	}
+	return cl, nil
+}
+
+// spnegoAuth adds a SPNEGO token to a request.
+type spnegoAuth interface {
+	setHeader(req *http.Request) error
+}
+
+// kerberosAuth obtains the tokens from a logged in Kerberos client.
+type kerberosAuth struct {
+	client *krb5client.Client
+}
+
+func (a kerberosAuth) setHeader(req *http.Request) error {
+	// An empty SPN makes gokrb5 use HTTP/<host of url>.
+	return spnego.SetSPNEGOHeader(a.client, req, "")
+}
+
+// negotiate reports whether resp asks for SPNEGO authentication, which the
+// Hadoop web UIs do when hadoop.http.authentication.type is kerberos.
+func negotiate(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	for _, h := range resp.Header[http.CanonicalHeaderKey(spnego.HTTPHeaderAuthResponse)] {
+		if strings.HasPrefix(h, spnego.HTTPHeaderAuthResponseValueKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+module github.com/rusonding/hadoop_exporter
+
+go 1.21
+
+require (
+	github.com/jcmturner/gokrb5/v8 v8.4.4
+	github.com/prometheus/client_golang v0.9.4
+	github.com/prometheus/client_model v0.0.0-20190129233127-fd36f4220a90
+	github.com/prometheus/common v0.4.1
+	golang.org/x/crypto v0.6.0
+	gopkg.in/yaml.v2 v2.2.2
+)
+
+require (
+	github.com/beorn7/perks v1.0.0 // indirect
+	github.com/golang/protobuf v1.3.1 // indirect
+	github.com/hashicorp/go-uuid v1.0.3 // indirect
+	github.com/jcmturner/aescts/v2 v2.0.0 // indirect
+	github.com/jcmturner/dnsutils/v2 v2.0.0 // indirect
+	github.com/jcmturner/gofork v1.7.6 // indirect
+	github.com/jcmturner/goidentity/v6 v6.0.1 // indirect
+	github.com/jcmturner/rpc/v2 v2.0.3 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/prometheus/procfs v0.0.2 // indirect
+	golang.org/x/net v0.7.0 // indirect
+)
@@ -0,0 +1,44 @@
+package jmx
+
+import (
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cumulative matches the attributes that only grow while the daemon runs,
+// such as the NumOps of a MutableRate, the GC counts and times or the
+// ResourceManager's appsCompleted, but not the windowed NumOps below. They are
+// exported as counters unless a rule gives another type.
+var cumulative = regexp.MustCompile(`^(?:` +
+	`.*Ops|` +
+	`GcCount.*|GcTimeMillis.*|GcNum(Info|Warn)ThresholdExceeded|GcTotalExtraSleepTime|` +
+	`CollectionCount|CollectionTime|` +
+	`Log(Fatal|Error|Warn|Info)|` +
+	`Files(Created|Appended|Renamed|Deleted)|GetBlockLocations|` +
+	`(Remote)?Bytes(Read|Written)|(Received|Sent)Bytes|` +
+	`Blocks(Read|Written|Replicated|Removed|Verified|Cached|Uncached)|` +
+	`(Reads|Writes)From(Local|Remote)Client|Total(Read|Write)Time|` +
+	`DatanodeNetworkErrors|VolumeFailures|` +
+	`(Batches|Txns)Written(WhileLagging)?|` +
+	`Containers(Launched|Completed|Failed|Killed)|Shuffle(OutputBytes|OutputsFailed|OutputsOK)|` +
+	`apps(Submitted|Completed|Failed|Killed)` +
+	`)$`)
+
+// windowed matches the NumOps of a MutableQuantiles, such as Syncs60sNumOps,
+// which counts the operations of the last interval only and so goes down
+// whenever an interval had fewer.
+var windowed = regexp.MustCompile(`\d+sNumOps$`)
+
+// monotonic reports whether attr only grows while the daemon runs.
+func monotonic(attr string) bool {
+	return cumulative.MatchString(attr) && !windowed.MatchString(attr)
+}
+
+// defaultType returns the type of attr when no rule gives one.
+func defaultType(attr string) prometheus.ValueType {
+	if monotonic(attr) {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
+}
@@ -0,0 +1,50 @@
+package jmx
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestDefaultType(t *testing.T) {
+	for attr, want := range map[string]prometheus.ValueType{
+		"RpcQueueTimeNumOps":     prometheus.CounterValue,
+		"SyncsNumOps":            prometheus.CounterValue,
+		"CreateFileOps":          prometheus.CounterValue,
+		"Syncs60sNumOps":         prometheus.GaugeValue,
+		"RpcQueueTime300sNumOps": prometheus.GaugeValue,
+		"CallQueueLength":        prometheus.GaugeValue,
+	} {
+		if got := defaultType(attr); got != want {
+			t.Errorf("defaultType(%q) = %v, want %v", attr, got, want)
+		}
+	}
+}
+
+func journalResponse(syncs60s, syncs float64) *Response {
+	return &Response{Beans: []Bean{{
+		"name":           "Hadoop:service=JournalNode,name=Journal-ns1",
+		"Syncs60sNumOps": syncs60s,
+		"SyncsNumOps":    syncs,
+	}}}
+}
+
+func collect(m *Mapper, r *Response) bool {
+	ch := make(chan prometheus.Metric, 10)
+	defer close(ch)
+	return m.Collect(r, ch)
+}
+
+func TestCollectIgnoresWindowedDrops(t *testing.T) {
+	m := NewMapper("journalnode", nil)
+	syncs := 100.0
+	for _, syncs60s := range []float64{50, 20, 70, 10} {
+		syncs += syncs60s
+		if collect(m, journalResponse(syncs60s, syncs)) {
+			t.Errorf("Syncs60sNumOps dropping to %v reported a restart", syncs60s)
+		}
+	}
+	if !collect(m, journalResponse(5, 5)) {
+		t.Error("SyncsNumOps dropping reported no restart")
+	}
+}
@@ -0,0 +1,126 @@
+// Package jmx reads the JSON document served by the /jmx servlet of a
+// Hadoop daemon and walks the numeric attributes of its beans.
+package jmx
+
+import (
+	"sort"
+	"strings"
+)
+
+// Response is the document served by /jmx:
+// {"beans":[{"name":"Hadoop:service=NameNode,name=FSNamesystem", ...}, ...]}
+type Response struct {
+	Beans []Bean `json:"beans"`
+}
+
+// Bean returns the bean named name.
+func (r *Response) Bean(name string) (Bean, bool) {
+	for _, b := range r.Beans {
+		if b.Name() == name {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// Bean is a single MBean with its attributes keyed by name.
+type Bean map[string]interface{}
+
+// Name returns the object name of the bean,
+// e.g. "Hadoop:service=NameNode,name=FSNamesystem".
+func (b Bean) Name() string {
+	name, _ := b["name"].(string)
+	return name
+}
+
+// Float returns the numeric attribute attr. It reports false when the
+// attribute is absent, null or of another type.
+func (b Bean) Float(attr string) (float64, bool) {
+	v, ok := b[attr].(float64)
+	return v, ok
+}
+
+// String returns the string attribute attr. It reports false when the
+// attribute is absent, null or of another type.
+func (b Bean) String(attr string) (string, bool) {
+	v, ok := b[attr].(string)
+	return v, ok
+}
+
+// Object returns the composite attribute attr, such as HeapMemoryUsage, as
+// a Bean without a name.
+func (b Bean) Object(attr string) (Bean, bool) {
+	v, ok := b[attr].(map[string]interface{})
+	return Bean(v), ok
+}
+
+// Properties returns the key properties of the bean's object name,
+// e.g. {"service": "NameNode", "name": "FSNamesystem"}.
+func (b Bean) Properties() map[string]string {
+	_, props := ParseObjectName(b.Name())
+	return props
+}
+
+// ParseObjectName splits a JMX object name such as
+// "java.lang:type=MemoryPool,name=Code Cache" into its domain and key
+// properties.
+func ParseObjectName(name string) (string, map[string]string) {
+	props := map[string]string{}
+	i := strings.Index(name, ":")
+	if i < 0 {
+		return name, props
+	}
+	for _, kv := range strings.Split(name[i+1:], ",") {
+		if j := strings.Index(kv, "="); j > 0 {
+			props[kv[:j]] = kv[j+1:]
+		}
+	}
+	return name[:i], props
+}
+
+// Walk calls fn for every numeric or boolean attribute of every bean.
+// Composite attributes such as HeapMemoryUsage are flattened, so fn sees
+// "HeapMemoryUsage.used"; strings, arrays and nulls are skipped.
+func (r *Response) Walk(fn func(b Bean, attr string, value float64)) {
+	for _, b := range r.Beans {
+		walk(b, "", map[string]interface{}(b), fn)
+	}
+}
+
+func walk(b Bean, prefix string, attrs map[string]interface{}, fn func(Bean, string, float64)) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attr := prefix + k
+		switch v := attrs[k].(type) {
+		case float64:
+			fn(b, attr, v)
+		case bool:
+			if v {
+				fn(b, attr, 1)
+			} else {
+				fn(b, attr, 0)
+			}
+		case map[string]interface{}:
+			walk(b, attr+".", v, fn)
+		}
+	}
+}
+
+// MetricName turns an attribute name into a valid Prometheus metric name
+// component, replacing anything outside [a-zA-Z0-9_] with an underscore.
+func MetricName(attr string) string {
+	name := []byte(attr)
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		return "_" + string(name)
+	}
+	return string(name)
+}
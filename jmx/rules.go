@@ -0,0 +1,241 @@
+package jmx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the rules file given with -rules.file:
+//
+//	rules:
+//	- pattern: 'Hadoop:service=NameNode,name=FSNamesystem/(MissingBlocks|CorruptBlocks)'
+//	  name: hdfs_${1}
+//	  type: gauge
+//	  labels:
+//	    cluster: prod
+//
+// Each attribute is matched as "<bean name>/<attribute>", with composite
+// attributes flattened as in Walk, e.g. "java.lang:type=Memory/HeapMemoryUsage.used".
+// The first matching rule wins and attributes no rule matches are dropped.
+// Without rules every attribute is exported under its default name.
+type Config struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule maps the attributes matching Pattern onto a metric.
+type Rule struct {
+	// Pattern is a regular expression anchored at both ends.
+	Pattern string `yaml:"pattern"`
+	// Name is the metric name; ${1} or ${name} expand to the pattern's
+	// groups, characters invalid in a metric name turning into underscores.
+	// When empty the default namespaced attribute name is used.
+	Name string `yaml:"name"`
+	// Type is gauge, counter or untyped. When empty, cumulative attributes
+	// such as NumOps or GC counts are counters and all others gauges.
+	Type string `yaml:"type"`
+	Help string `yaml:"help"`
+	// Labels replace the default service, name, type and sub labels. Values
+	// expand the pattern's groups like Name.
+	Labels map[string]string `yaml:"labels"`
+	// ValueFactor multiplies the value, e.g. 0.001 to turn millis into seconds.
+	ValueFactor float64 `yaml:"value_factor"`
+
+	regex      *regexp.Regexp
+	valueType  prometheus.ValueType // 0 for the default type
+	labelNames []string
+}
+
+// LoadConfig reads and validates a rules file.
+func LoadConfig(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", file, err)
+	}
+	for i, r := range cfg.Rules {
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %v", file, i, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (r *Rule) compile() error {
+	var err error
+	if r.regex, err = regexp.Compile("^(?:" + r.Pattern + ")$"); err != nil {
+		return err
+	}
+	switch r.Type {
+	case "":
+	case "gauge":
+		r.valueType = prometheus.GaugeValue
+	case "counter":
+		r.valueType = prometheus.CounterValue
+	case "untyped":
+		r.valueType = prometheus.UntypedValue
+	default:
+		return fmt.Errorf("unknown type %q", r.Type)
+	}
+	if r.ValueFactor == 0 {
+		r.ValueFactor = 1
+	}
+	if r.Name != "" && !strings.Contains(r.Name, "$") && !model.IsValidMetricName(model.LabelValue(r.Name)) {
+		return fmt.Errorf("invalid metric name %q", r.Name)
+	}
+	for name := range r.Labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		r.labelNames = append(r.labelNames, name)
+	}
+	sort.Strings(r.labelNames)
+	return nil
+}
+
+// beanLabels are the key properties of a bean name labeling its metrics by
+// default. sub tells apart beans such as MetricsSystem,sub=Stats and
+// MetricsSystem,sub=Control.
+var beanLabels = []string{"service", "name", "type", "sub"}
+
+// Mapper turns bean attributes into metrics, applying the rules of a Config.
+type Mapper struct {
+	namespace string
+	rules     []*Rule
+
+	mtx sync.Mutex
+	// counters holds the counter values of the previous Collect.
+	counters map[string]float64
+	// logged holds the problems already logged, to log each only once.
+	logged map[string]bool
+}
+
+// NewMapper returns a Mapper whose default metric names are prefixed with
+// namespace. cfg may be nil.
+func NewMapper(namespace string, cfg *Config) *Mapper {
+	m := &Mapper{namespace: namespace, logged: map[string]bool{}}
+	if cfg != nil {
+		m.rules = cfg.Rules
+	}
+	return m
+}
+
+// Collect sends a metric for every attribute of r that the rules keep. Null
+// attributes are skipped. It reports whether a monotonic counter is lower than
+// on the previous Collect, meaning the daemon restarted.
+func (m *Mapper) Collect(r *Response, ch chan<- prometheus.Metric) bool {
+	seen := map[string]bool{}
+	counters := map[string]float64{}
+	r.Walk(func(b Bean, attr string, value float64) {
+		metric, key, valueType, ok := m.metric(b, attr, value)
+		if !ok {
+			return
+		}
+		// The same attribute can show up in two beans whose labels are
+		// identical; the registry rejects the whole scrape on duplicates.
+		if seen[key] {
+			m.logOnce("dropping %s/%s: an earlier attribute produced the same metric and labels", b.Name(), attr)
+			return
+		}
+		seen[key] = true
+		// Only attributes known to be monotonic tell a restart, a rule may
+		// type anything as a counter.
+		if valueType == prometheus.CounterValue && monotonic(attr) {
+			counters[key] = value
+		}
+		ch <- metric
+	})
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	reset := false
+	for key, value := range counters {
+		if last, ok := m.counters[key]; ok && value < last {
+			reset = true
+		}
+	}
+	m.counters = counters
+	return reset
+}
+
+// logOnce logs a problem of the mapping the first time it is seen, as it
+// repeats on every scrape.
+func (m *Mapper) logOnce(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.logged[msg] {
+		return
+	}
+	m.logged[msg] = true
+	log.Print(msg)
+}
+
+// metric maps a single attribute. The returned key identifies the series.
+func (m *Mapper) metric(b Bean, attr string, value float64) (prometheus.Metric, string, prometheus.ValueType, bool) {
+	name := prometheus.BuildFQName(m.namespace, "", MetricName(attr))
+	help := MetricName(attr)
+	valueType := defaultType(attr)
+	labelNames := beanLabels
+	props := b.Properties()
+	labelValues := make([]string, len(beanLabels))
+	for i, l := range beanLabels {
+		labelValues[i] = props[l]
+	}
+
+	if len(m.rules) > 0 {
+		s := b.Name() + "/" + attr
+		var rule *Rule
+		var match []int
+		for _, r := range m.rules {
+			if match = r.regex.FindStringSubmatchIndex(s); match != nil {
+				rule = r
+				break
+			}
+		}
+		if rule == nil {
+			return nil, "", 0, false
+		}
+		expand := func(template string) string {
+			return string(rule.regex.ExpandString(nil, template, s, match))
+		}
+		if rule.Name != "" {
+			name = MetricName(expand(rule.Name))
+			help = name
+		}
+		if rule.Help != "" {
+			help = rule.Help
+		}
+		if len(rule.labelNames) > 0 {
+			labelNames = rule.labelNames
+			labelValues = make([]string, len(labelNames))
+			for i, l := range labelNames {
+				labelValues[i] = expand(rule.Labels[l])
+			}
+		}
+		if rule.valueType != 0 {
+			valueType = rule.valueType
+		}
+		value *= rule.ValueFactor
+	}
+
+	desc := prometheus.NewDesc(name, help, labelNames, nil)
+	metric, err := prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+	if err != nil {
+		// E.g. a rule name expanding to a group that did not match.
+		m.logOnce("dropping %s/%s: %v", b.Name(), attr, err)
+		return nil, "", 0, false
+	}
+	return metric, name + "\xff" + strings.Join(labelValues, "\xff"), valueType, true
+}
@@ -0,0 +1,226 @@
+package jmx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// series returns what m maps r to as sorted "name{label=value,...} value"
+// lines.
+func series(t *testing.T, m *Mapper, r *Response) []string {
+	ch := make(chan prometheus.Metric, 100)
+	m.Collect(r, ch)
+	close(ch)
+	var out []string
+	for metric := range ch {
+		var pb dto.Metric
+		if err := metric.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		var labels []string
+		for _, l := range pb.Label {
+			labels = append(labels, l.GetName()+"="+l.GetValue())
+		}
+		v := pb.GetGauge().GetValue() + pb.GetCounter().GetValue() + pb.GetUntyped().GetValue()
+		out = append(out, fmt.Sprintf("%s{%s} %g", fqName(metric.Desc()), strings.Join(labels, ","), v))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// fqName extracts the metric name from the description of desc, which has
+// no accessor for it.
+func fqName(desc *prometheus.Desc) string {
+	s := desc.String()
+	s = s[strings.Index(s, `"`)+1:]
+	return s[:strings.Index(s, `"`)]
+}
+
+func checkSeries(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMapperSubLabel(t *testing.T) {
+	r := &Response{Beans: []Bean{
+		{"name": "Hadoop:service=NameNode,name=MetricsSystem,sub=Stats", "NumActiveSources": 12.0},
+		{"name": "Hadoop:service=NameNode,name=MetricsSystem,sub=Control", "NumActiveSources": 3.0},
+	}}
+	got := series(t, NewMapper("namenode", nil), r)
+	want := []string{
+		"namenode_NumActiveSources{name=MetricsSystem,service=NameNode,sub=Control,type=} 3",
+		"namenode_NumActiveSources{name=MetricsSystem,service=NameNode,sub=Stats,type=} 12",
+	}
+	checkSeries(t, got, want)
+}
+
+// loadRules writes yml to a rules file and loads it.
+func loadRules(t *testing.T, yml string) (*Config, error) {
+	file := filepath.Join(t.TempDir(), "rules.yml")
+	if err := os.WriteFile(file, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(file)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, tc := range []struct{ yml, err string }{
+		{"rules:\n- pattern: 'a'\n  nmae: b\n", "field nmae not found"},
+		{"rules:\n- pattern: '('\n", "missing closing )"},
+		{"rules:\n- pattern: 'a'\n  type: summary\n", `unknown type "summary"`},
+		{"rules:\n- pattern: 'a'\n  labels:\n    rack-id: x\n", `invalid label name "rack-id"`},
+		{"rules:\n- pattern: 'a'\n  labels:\n    1st: x\n", `invalid label name "1st"`},
+		{"rules:\n- pattern: 'a'\n  name: 9lives\n", `invalid metric name "9lives"`},
+		{"rules:\n- pattern: 'a'\n  name: hdfs-blocks\n", `invalid metric name "hdfs-blocks"`},
+	} {
+		_, err := loadRules(t, tc.yml)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("LoadConfig(%q) = %v, want error containing %q", tc.yml, err, tc.err)
+		}
+	}
+}
+
+var fsNamesystem = &Response{Beans: []Bean{{
+	"name":           "Hadoop:service=NameNode,name=FSNamesystem",
+	"MissingBlocks":  2.0,
+	"CorruptBlocks":  1.0,
+	"BlocksTotal":    100.0,
+	"GcTimeMillis":   1500.0,
+	"FilesTotal":     10.0,
+	"tag.HAState":    "active",
+	"CapacityUsed":   nil,
+	"SnapshotsTotal": 0.0,
+}}}
+
+func TestMapperRules(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		yml  string
+		want []string
+	}{
+		{
+			"unmatched attributes are dropped and patterns anchored",
+			`rules:
+- pattern: 'Hadoop:service=NameNode,name=FSNamesystem/MissingBlocks'
+- pattern: 'FSNamesystem/CorruptBlocks'
+- pattern: 'Hadoop:service=NameNode,name=FSNamesystem/Blocks'
+`,
+			[]string{"namenode_MissingBlocks{name=FSNamesystem,service=NameNode,sub=,type=} 2"},
+		},
+		{
+			"first match wins",
+			`rules:
+- pattern: '.*/MissingBlocks'
+  name: first
+- pattern: '.*/(MissingBlocks|CorruptBlocks)'
+  name: second
+`,
+			[]string{
+				"first{name=FSNamesystem,service=NameNode,sub=,type=} 2",
+				"second{name=FSNamesystem,service=NameNode,sub=,type=} 1",
+			},
+		},
+		{
+			"numbered and named groups expand in name and labels",
+			`rules:
+- pattern: 'Hadoop:service=(?P<service>\w+),name=FSNamesystem/(Missing|Corrupt)Blocks'
+  name: hdfs_${2}_blocks
+  labels:
+    daemon: ${service}
+    cluster: prod
+`,
+			[]string{
+				"hdfs_Corrupt_blocks{cluster=prod,daemon=NameNode} 1",
+				"hdfs_Missing_blocks{cluster=prod,daemon=NameNode} 2",
+			},
+		},
+		{
+			"expanded names are sanitized",
+			`rules:
+- pattern: 'Hadoop:service=NameNode,name=(FS)Namesystem/MissingBlocks'
+  name: hdfs.${1}-missing
+`,
+			[]string{"hdfs_FS_missing{name=FSNamesystem,service=NameNode,sub=,type=} 2"},
+		},
+		{
+			"value_factor scales the value",
+			`rules:
+- pattern: '.*/GcTimeMillis'
+  name: gc_seconds_total
+  value_factor: 0.001
+`,
+			[]string{"gc_seconds_total{name=FSNamesystem,service=NameNode,sub=,type=} 1.5"},
+		},
+		{
+			"a name expanding to nothing drops the attribute",
+			`rules:
+- pattern: '.*/(FilesTotal)'
+  name: '${2}'
+- pattern: '.*/SnapshotsTotal'
+`,
+			[]string{"namenode_SnapshotsTotal{name=FSNamesystem,service=NameNode,sub=,type=} 0"},
+		},
+	} {
+		cfg, err := loadRules(t, tc.yml)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		got := series(t, NewMapper("namenode", cfg), fsNamesystem)
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.name, strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+		}
+	}
+}
+
+func TestMapperTypes(t *testing.T) {
+	cfg, err := loadRules(t, `rules:
+- pattern: '.*/GcTimeMillis'
+  type: gauge
+- pattern: '.*/FilesTotal'
+  type: counter
+- pattern: '.*/BlocksTotal'
+  type: untyped
+- pattern: '.*/MissingBlocks'
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan prometheus.Metric, 10)
+	NewMapper("namenode", cfg).Collect(fsNamesystem, ch)
+	close(ch)
+	want := map[string]string{
+		"namenode_GcTimeMillis":  "gauge",
+		"namenode_FilesTotal":    "counter",
+		"namenode_BlocksTotal":   "untyped",
+		"namenode_MissingBlocks": "gauge",
+	}
+	for metric := range ch {
+		var pb dto.Metric
+		if err := metric.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		got := "untyped"
+		if pb.Gauge != nil {
+			got = "gauge"
+		} else if pb.Counter != nil {
+			got = "counter"
+		}
+		name := fqName(metric.Desc())
+		if got != want[name] {
+			t.Errorf("%s is a %s, want %s", name, got, want[name])
+		}
+		delete(want, name)
+	}
+	for name := range want {
+		t.Errorf("%s is missing", name)
+	}
+}
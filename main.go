@@ -0,0 +1,257 @@
+// Command hadoop_exporter exports metrics of Hadoop daemons for Prometheus.
+// The -role flag selects which daemon is scraped.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rusonding/hadoop_exporter/collector"
+	"github.com/rusonding/hadoop_exporter/fetch"
+	"github.com/rusonding/hadoop_exporter/jmx"
+	"github.com/rusonding/hadoop_exporter/web"
+	"github.com/rusonding/hadoop_exporter/zookeeper"
+)
+
+var (
+	roleName      = flag.String("role", "", "Hadoop role to export on the metrics path: "+strings.Join(roleNames(), ", ")+". Without it only /probe scrapes Hadoop.")
+	listenAddress = flag.String("web.listen-address", "", "Address on which to expose metrics and web interface. (default depends on -role)")
+	metricsPath   = flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+	webConfigFile = flag.String("web.config.file", "", "YAML file enabling HTTPS and basic auth on the exporter's own endpoints.")
+	rulesFile     = flag.String("rules.file", "", "YAML file with rules mapping JMX attributes to metrics.")
+
+	namenodeJmxUrl     = flag.String("namenode.jmx.url", "http://localhost:50070/jmx", "Hadoop NameNode JMX URL.")
+	nameserviceUrls    = flag.String("namenode.nameservice.urls", "", "Comma-separated JMX URLs of all NameNodes of an HA nameservice, to check that exactly one is active.")
+	datanodeJmxUrl     = flag.String("datanode.jmx.url", "http://localhost:50075/jmx", "Hadoop DataNode JMX URL.")
+	journalnodeJmxUrl  = flag.String("journalnode.jmx.url", "http://localhost:8480/jmx", "Hadoop JournalNode JMX URL.")
+	nodeManagerUrl     = flag.String("nodemanager.url", "http://localhost:8042", "Hadoop NodeManager URL.")
+	resourceManagerUrl = flag.String("resourcemanager.url", "http://localhost:8088", "Hadoop ResourceManager URL.")
+	appsTopN           = flag.Int("resourcemanager.apps.top-n", 0, "Export the N running and accepted applications allocating the most memory. 0 disables per-application metrics.")
+	appsLabels         = flag.String("resourcemanager.apps.labels", "queue,user,state", "Comma-separated labels of the per-application metrics besides the application id: "+strings.Join(appLabelNames(), ", ")+".")
+
+	zookeeperHost        = flag.String("zookeeper-host", "localhost", "ZooKeeper host, or a comma-separated list or connect string such as zk1:2181,zk2:2181,zk3:2181/chroot scraping the whole ensemble.")
+	zookeeperPort        = flag.Int("zookeeper.port", 2181, "ZooKeeper client port, used unless -zookeeper-host gives one.")
+	zookeeperDialTimeout = flag.Duration("zookeeper.connect-timeout", 5*time.Second, "Timeout connecting to ZooKeeper.")
+	zookeeperReadTimeout = flag.Duration("zookeeper.read-timeout", 5*time.Second, "Timeout for ZooKeeper to answer a four letter word.")
+
+	httpTimeout = flag.Duration("http.timeout", 10*time.Second, "Timeout of each request to the Hadoop web UIs.")
+
+	tlsCAFile             = flag.String("tls.ca-file", "", "PEM file with the CAs verifying HTTPS Hadoop web UIs. (default system roots)")
+	tlsCertFile           = flag.String("tls.cert-file", "", "PEM client certificate presented to HTTPS Hadoop web UIs.")
+	tlsKeyFile            = flag.String("tls.key-file", "", "PEM key of -tls.cert-file.")
+	tlsServerName         = flag.String("tls.server-name", "", "Server name verified against the certificates of HTTPS Hadoop web UIs.")
+	tlsInsecureSkipVerify = flag.Bool("tls.insecure-skip-verify", false, "Do not verify the certificates of HTTPS Hadoop web UIs.")
+
+	kerberosPrincipal = flag.String("kerberos.principal", "", "Kerberos principal, e.g. prometheus/host@EXAMPLE.COM, enabling SPNEGO authentication to the Hadoop web UIs.")
+	kerberosKeytab    = flag.String("kerberos.keytab", "", "Keytab holding the keys of -kerberos.principal.")
+	krb5Conf          = flag.String("kerberos.krb5-conf", "/etc/krb5.conf", "krb5.conf locating the KDC of the principal's realm.")
+)
+
+type role struct {
+	title         string
+	listenAddress string
+	url           *string
+	// path completes probe targets given as host:port.
+	path string
+	// tcp marks roles scraped over plain TCP, whose probe targets are
+	// host:port addresses rather than URLs.
+	tcp bool
+	// newCollector builds the role's collector scraping url.
+	newCollector func(client *fetch.Client, url string, rules *jmx.Config) prometheus.Collector
+}
+
+var roles = map[string]role{
+	"namenode": {
+		title:         "NameNode Exporter",
+		listenAddress: ":9070",
+		url:           namenodeJmxUrl,
+		path:          "/jmx",
+		newCollector: func(client *fetch.Client, url string, rules *jmx.Config) prometheus.Collector {
+			return collector.NewNameNode(client, url, rules)
+		},
+	},
+	"datanode": {
+		title:         "DataNode Exporter",
+		listenAddress: ":9077",
+		url:           datanodeJmxUrl,
+		path:          "/jmx",
+		newCollector: func(client *fetch.Client, url string, rules *jmx.Config) prometheus.Collector {
+			return collector.NewDataNode(client, url, rules)
+		},
+	},
+	"journalnode": {
+		title:         "JournalNode Exporter",
+		listenAddress: ":9080",
+		url:           journalnodeJmxUrl,
+		path:          "/jmx",
+		newCollector: func(client *fetch.Client, url string, rules *jmx.Config) prometheus.Collector {
+			return collector.NewJournalNode(client, url, rules)
+		},
+	},
+	"nodemanager": {
+		title:         "NodeManager Exporter",
+		listenAddress: ":9082",
+		url:           nodeManagerUrl,
+		newCollector: func(client *fetch.Client, url string, rules *jmx.Config) prometheus.Collector {
+			return collector.NewNodeManager(client, url, rules)
+		},
+	},
+	"resourcemanager": {
+		title:         "ResourceManager Exporter",
+		listenAddress: ":9088",
+		url:           resourceManagerUrl,
+		newCollector: func(client *fetch.Client, url string, rules *jmx.Config) prometheus.Collector {
+			return collector.NewResourceManager(client, url, rules, appsConfig)
+		},
+	},
+	"zookeeper": {
+		title:         "Zookeeper Exporter",
+		listenAddress: ":9079",
+		url:           zookeeperHost,
+		tcp:           true,
+		newCollector: func(_ *fetch.Client, hosts string, _ *jmx.Config) prometheus.Collector {
+			addrs := zookeeper.Addrs(hosts, *zookeeperPort)
+			if len(addrs) == 1 {
+				return collector.NewZooKeeper(zkClient, addrs[0])
+			}
+			return collector.NewZooKeeperEnsemble(zkClient, addrs)
+		},
+	},
+}
+
+// zkClient is set from the -zookeeper.*-timeout flags.
+var zkClient *zookeeper.Client
+
+// appsConfig is set from the -resourcemanager.apps.* flags.
+var appsConfig collector.AppsConfig
+
+func appLabelNames() []string {
+	var names []string
+	for name := range collector.AppLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func roleNames() []string {
+	var names []string
+	for name := range roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// targetURL turns a probe target such as "host:50075" into the URL scraped
+// by the role's collector.
+func (r role) targetURL(target string) (string, error) {
+	if r.tcp {
+		return target, nil
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	if u.Path == "" {
+		u.Path = r.path
+	}
+	return u.String(), nil
+}
+
+func main() {
+	flag.Parse()
+
+	r, ok := roles[*roleName]
+	if !ok && *roleName != "" {
+		fmt.Fprintf(os.Stderr, "-role must be one of %s\n", strings.Join(roleNames(), ", "))
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *listenAddress == "" {
+		*listenAddress = r.listenAddress
+	}
+	if *listenAddress == "" {
+		*listenAddress = ":9070"
+	}
+
+	appsConfig.TopN = *appsTopN
+	if *appsLabels != "" {
+		for _, l := range strings.Split(*appsLabels, ",") {
+			if _, ok := collector.AppLabels[l]; !ok {
+				log.Fatalf("-resourcemanager.apps.labels: unknown label %q, must be one of %s", l, strings.Join(appLabelNames(), ", "))
+			}
+			for _, seen := range appsConfig.Labels {
+				if l == seen {
+					log.Fatalf("-resourcemanager.apps.labels: duplicate label %q", l)
+				}
+			}
+			appsConfig.Labels = append(appsConfig.Labels, l)
+		}
+	}
+
+	var rules *jmx.Config
+	if *rulesFile != "" {
+		var err error
+		if rules, err = jmx.LoadConfig(*rulesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	client, err := fetch.NewClient(fetch.Config{
+		TLSCAFile:             *tlsCAFile,
+		TLSCertFile:           *tlsCertFile,
+		TLSKeyFile:            *tlsKeyFile,
+		TLSServerName:         *tlsServerName,
+		TLSInsecureSkipVerify: *tlsInsecureSkipVerify,
+		KerberosPrincipal:     *kerberosPrincipal,
+		KerberosKeytab:        *kerberosKeytab,
+		Krb5Conf:              *krb5Conf,
+		Timeout:               *httpTimeout,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	zkClient = &zookeeper.Client{DialTimeout: *zookeeperDialTimeout, ReadTimeout: *zookeeperReadTimeout}
+	if r.newCollector != nil {
+		prometheus.MustRegister(r.newCollector(client, *r.url, rules))
+		if *roleName == "namenode" && *nameserviceUrls != "" {
+			prometheus.MustRegister(collector.NewNameService(client, strings.Split(*nameserviceUrls, ",")))
+		}
+	}
+
+	log.Printf("Starting %s on %s", *roleName, *listenAddress)
+	mux := http.NewServeMux()
+	mux.Handle(*metricsPath, promhttp.Handler())
+	mux.Handle("/probe", web.ProbeHandler(func(module, target string) (prometheus.Collector, error) {
+		if module == "" {
+			module = *roleName
+		}
+		m, ok := roles[module]
+		if !ok {
+			return nil, fmt.Errorf("unknown module %q", module)
+		}
+		u, err := m.targetURL(target)
+		if err != nil {
+			return nil, err
+		}
+		return m.newCollector(client, u, rules), nil
+	}))
+	title := r.title
+	if title == "" {
+		title = "Hadoop Exporter"
+	}
+	mux.Handle("/", web.LandingPage(title, *metricsPath))
+	log.Fatal(web.ListenAndServe(*listenAddress, *webConfigFile, mux))
+}
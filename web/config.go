@@ -0,0 +1,173 @@
+package web
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the file given with -web.config.file, in the format of the
+// Prometheus exporter-toolkit:
+//
+//	tls_server_config:
+//	  cert_file: server.crt
+//	  key_file: server.key
+//	  client_auth_type: RequireAndVerifyClientCert
+//	  client_ca_file: ca.crt
+//	basic_auth_users:
+//	  prometheus: $2y$10$...   # bcrypt hash of the password
+type Config struct {
+	TLSConfig      TLSConfig         `yaml:"tls_server_config"`
+	BasicAuthUsers map[string]string `yaml:"basic_auth_users"`
+}
+
+// TLSConfig enables HTTPS when CertFile and KeyFile are set.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientAuth is one of the crypto/tls ClientAuthType names, e.g.
+	// RequireAndVerifyClientCert.
+	ClientAuth   string `yaml:"client_auth_type"`
+	ClientCAFile string `yaml:"client_ca_file"`
+	// MinVersion is TLS10, TLS11, TLS12 (the default) or TLS13.
+	MinVersion string `yaml:"min_version"`
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                           tls.NoClientCert,
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+var tlsVersions = map[string]uint16{
+	"":      tls.VersionTLS12,
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// LoadConfig reads a web config file.
+func LoadConfig(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", file, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return cfg, nil
+}
+
+// validate catches mistakes that would otherwise only show on the first
+// request, or silently serve plain HTTP.
+func (c *Config) validate() error {
+	if err := c.TLSConfig.validate(); err != nil {
+		return err
+	}
+	for user, hash := range c.BasicAuthUsers {
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return fmt.Errorf("basic_auth_users: password of %s is not a bcrypt hash: %v", user, err)
+		}
+	}
+	return nil
+}
+
+func (c *TLSConfig) validate() error {
+	if *c == (TLSConfig{}) {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("tls_server_config needs both cert_file and key_file")
+	}
+	clientAuth, ok := clientAuthTypes[c.ClientAuth]
+	if !ok {
+		return fmt.Errorf("unknown client_auth_type %q", c.ClientAuth)
+	}
+	if _, ok := tlsVersions[c.MinVersion]; !ok {
+		return fmt.Errorf("unknown min_version %q", c.MinVersion)
+	}
+	if c.ClientCAFile == "" && (clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert) {
+		return fmt.Errorf("client_auth_type %s needs client_ca_file", c.ClientAuth)
+	}
+	return nil
+}
+
+func (c *TLSConfig) enabled() bool {
+	return c.CertFile != ""
+}
+
+func (c *TLSConfig) serverConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %v", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   clientAuthTypes[c.ClientAuth],
+		MinVersion:   tlsVersions[c.MinVersion],
+	}
+	if c.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.ClientCAFile)
+		}
+	}
+	return cfg, nil
+}
+
+// dummyHash is compared against for unknown users, so that the response
+// time does not tell which users exist.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost)
+
+// basicAuth requires one of users, mapping names to bcrypt hashes, on every
+// request to handler. Credentials that passed are remembered by their
+// SHA-256, as bcrypt is made to be slow and Prometheus sends the same ones
+// on every scrape.
+func basicAuth(users map[string]string, handler http.Handler) http.Handler {
+	var mu sync.Mutex
+	passed := map[[sha256.Size]byte]bool{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if ok {
+			hash, known := users[user]
+			if !known {
+				hash = string(dummyHash)
+			}
+			key := sha256.Sum256([]byte(user + "\x00" + hash + "\x00" + pass))
+			mu.Lock()
+			cached := passed[key]
+			mu.Unlock()
+			if !cached && bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass)) == nil && known {
+				mu.Lock()
+				passed[key] = true
+				mu.Unlock()
+				cached = true
+			}
+			if cached {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="hadoop_exporter"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loadConfig writes yml to a web config file and loads it.
+func loadConfig(t *testing.T, yml string) (*Config, error) {
+	file := filepath.Join(t.TempDir(), "web.yml")
+	if err := os.WriteFile(file, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(file)
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig(t, `tls_server_config:
+  cert_file: server.crt
+  key_file: server.key
+  client_auth_type: RequireAndVerifyClientCert
+  client_ca_file: ca.crt
+  min_version: TLS13
+basic_auth_users:
+  prometheus: $2a$10$oG.jOyPAAa7UaP3/N91weOK3dAFZIvIyXXmvByrvz5Sg/iUaUCBmG
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.TLSConfig.enabled() || len(cfg.BasicAuthUsers) != 1 {
+		t.Errorf("got %+v, want TLS and one user", cfg)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, tc := range []struct{ yml, err string }{
+		{"tls_server_config:\n  cert_fiel: server.crt\n", "field cert_fiel not found"},
+		{"tls_server_config:\n  cert_file: server.crt\n", "needs both cert_file and key_file"},
+		{"tls_server_config:\n  key_file: server.key\n", "needs both cert_file and key_file"},
+		{"tls_server_config:\n  client_ca_file: ca.crt\n", "needs both cert_file and key_file"},
+		{"tls_server_config:\n  min_version: TLS13\n", "needs both cert_file and key_file"},
+		{"tls_server_config:\n  cert_file: a\n  key_file: b\n  client_auth_type: RequireClientCert\n", `unknown client_auth_type "RequireClientCert"`},
+		{"tls_server_config:\n  cert_file: a\n  key_file: b\n  min_version: TLS14\n", `unknown min_version "TLS14"`},
+		{"tls_server_config:\n  cert_file: a\n  key_file: b\n  client_auth_type: RequireAndVerifyClientCert\n", "needs client_ca_file"},
+		{"tls_server_config:\n  cert_file: a\n  key_file: b\n  client_auth_type: VerifyClientCertIfGiven\n", "needs client_ca_file"},
+		{"basic_auth_users:\n  prometheus: secret\n", "password of prometheus is not a bcrypt hash"},
+	} {
+		_, err := loadConfig(t, tc.yml)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("LoadConfig(%q) = %v, want error containing %q", tc.yml, err, tc.err)
+		}
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(basicAuth(map[string]string{"prometheus": string(hash)},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("metrics"))
+		})))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		user, pass string
+		want       int
+	}{
+		{"prometheus", "secret", http.StatusOK},
+		{"prometheus", "secret", http.StatusOK},
+		{"prometheus", "wrong", http.StatusUnauthorized},
+		{"grafana", "secret", http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	} {
+		req, err := http.NewRequest("GET", srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.user != "" {
+			req.SetBasicAuth(tc.user, tc.pass)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s:%s got status %d, want %d", tc.user, tc.pass, resp.StatusCode, tc.want)
+		}
+		if tc.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("%s:%s got no WWW-Authenticate header", tc.user, tc.pass)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package web serves the exporter's own HTTP endpoints.
+package web
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// LandingPage returns a handler for "/" linking to the metrics path.
+func LandingPage(title, metricsPath string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html>
+		<head><title>` + title + `</title></head>
+		<body>
+		<h1>` + title + `</h1>
+		<p><a href="` + metricsPath + `">Metrics</a></p>
+		</body>
+		</html>`))
+	})
+}
+
+// ListenAndServe serves handler on addr, over HTTPS and behind basic auth as
+// configured by the web config file. configFile may be empty.
+func ListenAndServe(addr, configFile string, handler http.Handler) error {
+	if configFile == "" {
+		return http.ListenAndServe(addr, handler)
+	}
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	if len(cfg.BasicAuthUsers) > 0 {
+		handler = basicAuth(cfg.BasicAuthUsers, handler)
+	}
+	server := &http.Server{Addr: addr, Handler: handler}
+	if !cfg.TLSConfig.enabled() {
+		return server.ListenAndServe()
+	}
+	if server.TLSConfig, err = cfg.TLSConfig.serverConfig(); err != nil {
+		return err
+	}
+	return server.ListenAndServeTLS("", "")
+}
+
+// ProbeHandler serves /probe?target=host:port&module=datanode. Each request
+// scrapes target with a fresh collector returned by newCollector.
+func ProbeHandler(newCollector func(module, target string) (prometheus.Collector, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is missing", http.StatusBadRequest)
+			return
+		}
+		c, err := newCollector(r.URL.Query().Get("module"), target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(c)
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
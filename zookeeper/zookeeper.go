@@ -0,0 +1,151 @@
+// Package zookeeper queries ZooKeeper servers with the four letter word
+// commands served on their client port, such as mntr and ruok.
+package zookeeper
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commands are the four letter words a Client sends.
+var Commands = []string{"mntr", "srvr", "ruok", "stat", "wchs", "cons", "envi"}
+
+// Client sends four letter words over TCP.
+type Client struct {
+	// DialTimeout bounds connecting to the server.
+	DialTimeout time.Duration
+	// ReadTimeout bounds sending the command and reading the whole answer.
+	ReadTimeout time.Duration
+}
+
+// NotAllowedError is returned when the server does not execute a command
+// missing from its 4lw.commands.whitelist, as ZooKeeper 3.5 and later only
+// allow srvr by default.
+type NotAllowedError struct {
+	Addr, Command string
+}
+
+func (e *NotAllowedError) Error() string {
+	return fmt.Sprintf("%s does not allow %s, add it to 4lw.commands.whitelist", e.Addr, e.Command)
+}
+
+// Command sends cmd, one of Commands, to the server at addr and returns its
+// answer. The server closes the connection once it answered.
+func (c *Client) Command(addr, cmd string) (string, error) {
+	if !known(cmd) {
+		return "", fmt.Errorf("unknown four letter word %q", cmd)
+	}
+	conn, err := net.DialTimeout("tcp", addr, c.DialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if c.ReadTimeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(c.ReadTimeout)); err != nil {
+			return "", err
+		}
+	}
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return "", fmt.Errorf("sending %s to %s: %v", cmd, addr, err)
+	}
+	answer, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("reading %s from %s: %v", cmd, addr, err)
+	}
+	if strings.Contains(string(answer), "is not executed because it is not in the whitelist") {
+		return "", &NotAllowedError{Addr: addr, Command: cmd}
+	}
+	return string(answer), nil
+}
+
+// Ruok reports whether the server at addr answers ruok with imok, i.e. is
+// running without errors.
+func (c *Client) Ruok(addr string) (bool, error) {
+	answer, err := c.Command(addr, "ruok")
+	if err != nil {
+		return false, err
+	}
+	return answer == "imok", nil
+}
+
+// Mntr returns the keys and values the server at addr answers mntr with,
+// e.g. {"zk_server_state": "leader", "zk_znode_count": "42"}.
+func (c *Client) Mntr(addr string) (map[string]string, error) {
+	answer, err := c.Command(addr, "mntr")
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, line := range strings.Split(answer, "\n") {
+		kv := strings.SplitN(line, "\t", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		values[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s answered mntr with %q", addr, answer)
+	}
+	return values, nil
+}
+
+// Srvr returns the fields the server at addr answers srvr with, e.g.
+// {"Mode": "leader", "Zxid": "0x100000010"}. Unlike mntr, srvr is allowed by
+// default.
+func (c *Client) Srvr(addr string) (map[string]string, error) {
+	answer, err := c.Command(addr, "srvr")
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	for _, line := range strings.Split(answer, "\n") {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		fields[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return fields, nil
+}
+
+// Zxid parses a zxid as srvr prints it, e.g. "0x100000010".
+func Zxid(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+}
+
+// Addrs returns the addresses of the servers of a connect string such as
+// "zk1:2181,zk2:2181,zk3/hbase", appending port to hosts without one.
+func Addrs(connect string, port int) []string {
+	if i := strings.Index(connect, "/"); i >= 0 {
+		connect = connect[:i]
+	}
+	var addrs []string
+	for _, host := range strings.Split(connect, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			addrs = append(addrs, Addr(host, port))
+		}
+	}
+	return addrs
+}
+
+// Addr returns host with port appended unless it has one, e.g.
+// "zk1:2181" for "zk1".
+func Addr(host string, port int) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), fmt.Sprint(port))
+}
+
+func known(cmd string) bool {
+	for _, c := range Commands {
+		if c == cmd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package zookeeper
+
+import (
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mntr = "zk_version\t3.4.6-1569965, built on 02/20/2014 09:09 GMT\n" +
+	"zk_server_state\tleader\n" +
+	"zk_znode_count\t42\n" +
+	"zk_followers\t2\n"
+
+const srvr = "Zookeeper version: 3.4.6-1569965, built on 02/20/2014 09:09 GMT\n" +
+	"Latency min/avg/max: 0/1/20\n" +
+	"Zxid: 0x100000010\n" +
+	"Mode: leader\n" +
+	"Node count: 42\n"
+
+// fakeServer answers four letter words as a ZooKeeper server does, closing
+// the connection after each answer. Words missing from answers are rejected
+// as not whitelisted. A nil answers map never answers.
+func fakeServer(t *testing.T, answers map[string]string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				cmd := make([]byte, 4)
+				if _, err := io.ReadFull(conn, cmd); err != nil {
+					return
+				}
+				if answers == nil {
+					io.Copy(io.Discard, conn)
+					return
+				}
+				answer, ok := answers[string(cmd)]
+				if !ok {
+					answer = string(cmd) + " is not executed because it is not in the whitelist.\n"
+				}
+				io.WriteString(conn, answer)
+			}(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestCommand(t *testing.T) {
+	addr := fakeServer(t, map[string]string{"ruok": "imok"})
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+	answer, err := c.Command(addr, "ruok")
+	if err != nil || answer != "imok" {
+		t.Errorf("Command(ruok) = %q, %v, want imok", answer, err)
+	}
+	if _, err := c.Command(addr, "kill"); err == nil {
+		t.Error("Command(kill) sent an unknown word")
+	}
+}
+
+func TestCommandNotAllowed(t *testing.T) {
+	addr := fakeServer(t, map[string]string{"srvr": srvr})
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+	_, err := c.Mntr(addr)
+	e, ok := err.(*NotAllowedError)
+	if !ok {
+		t.Fatalf("Mntr returned %v, want a NotAllowedError", err)
+	}
+	if e.Addr != addr || e.Command != "mntr" {
+		t.Errorf("got %+v, want mntr of %s", e, addr)
+	}
+	if !strings.Contains(e.Error(), "4lw.commands.whitelist") {
+		t.Errorf("error %q does not name 4lw.commands.whitelist", e)
+	}
+}
+
+func TestCommandReadTimeout(t *testing.T) {
+	addr := fakeServer(t, nil)
+	c := &Client{DialTimeout: time.Second, ReadTimeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	if _, err := c.Command(addr, "mntr"); err == nil {
+		t.Fatal("got no error from a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("gave up after %v, want about 50ms", elapsed)
+	}
+}
+
+func TestCommandRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+	if _, err := c.Command(addr, "ruok"); err == nil {
+		t.Error("got no error from a closed port")
+	}
+}
+
+func TestRuok(t *testing.T) {
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+	for answer, want := range map[string]bool{"imok": true, "": false} {
+		addr := fakeServer(t, map[string]string{"ruok": answer})
+		if ok, err := c.Ruok(addr); err != nil || ok != want {
+			t.Errorf("Ruok answered %q = %v, %v, want %v", answer, ok, err, want)
+		}
+	}
+}
+
+func TestMntr(t *testing.T) {
+	addr := fakeServer(t, map[string]string{"mntr": mntr})
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+	values, err := c.Mntr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"zk_version":      "3.4.6-1569965, built on 02/20/2014 09:09 GMT",
+		"zk_server_state": "leader",
+		"zk_znode_count":  "42",
+		"zk_followers":    "2",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Mntr = %v, want %v", values, want)
+	}
+}
+
+func TestMntrEmpty(t *testing.T) {
+	addr := fakeServer(t, map[string]string{"mntr": "This ZooKeeper instance is not currently serving requests\n"})
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+	if values, err := c.Mntr(addr); err == nil {
+		t.Errorf("Mntr = %v, want an error", values)
+	}
+}
+
+func TestSrvr(t *testing.T) {
+	addr := fakeServer(t, map[string]string{"srvr": srvr})
+	c := &Client{DialTimeout: time.Second, ReadTimeout: time.Second}
+
+	fields, err := c.Srvr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"Zookeeper version":   "3.4.6-1569965, built on 02/20/2014 09:09 GMT",
+		"Latency min/avg/max": "0/1/20",
+		"Zxid":                "0x100000010",
+		"Mode":                "leader",
+		"Node count":          "42",
+	} {
+		if fields[key] != want {
+			t.Errorf("Srvr()[%q] = %q, want %q", key, fields[key], want)
+		}
+	}
+}
+
+func TestZxid(t *testing.T) {
+	for s, want := range map[string]uint64{
+		"0x100000010": 0x100000010,
+		"0x0":         0,
+		"1f":          0x1f,
+	} {
+		if got, err := Zxid(s); err != nil || got != want {
+			t.Errorf("Zxid(%q) = %#x, %v, want %#x", s, got, err, want)
+		}
+	}
+	if _, err := Zxid("0xzz"); err == nil {
+		t.Error(`Zxid("0xzz") returned no error`)
+	}
+}
+
+func TestAddrs(t *testing.T) {
+	for connect, want := range map[string][]string{
+		"localhost":                          {"localhost:2181"},
+		"zk1:2182":                           {"zk1:2182"},
+		"zk1,zk2:2182,zk3":                   {"zk1:2181", "zk2:2182", "zk3:2181"},
+		"zk1:2181,zk2:2181/hbase":            {"zk1:2181", "zk2:2181"},
+		" zk1 , zk2 ,":                       {"zk1:2181", "zk2:2181"},
+		"::1":                                {"[::1]:2181"},
+		"[::1]":                              {"[::1]:2181"},
+		"[::1]:2182,[fe80::1]/kafka/cluster": {"[::1]:2182", "[fe80::1]:2181"},
+		"":                                   nil,
+	} {
+		if got := Addrs(connect, 2181); !reflect.DeepEqual(got, want) {
+			t.Errorf("Addrs(%q) = %q, want %q", connect, got, want)
+		}
+	}
+}